@@ -1,7 +0,0 @@
-module dfs
-
-go 1.24.3
-
-replace shared => ../shared
-
-require shared v0.0.0-00010101000000-000000000000
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Pengaturan server. Urutan prioritas: bawaan < file JSON < environment < flag.
+type Config struct {
+	Addr           string   `json:"addr"`           // alamat listen, mis. ":8080"
+	DataDir        string   `json:"dataDir"`        // folder berisi elements.json dan images/
+	AllowedOrigins []string `json:"allowedOrigins"` // origin CORS yang diizinkan, "*" = semua
+	PublicURL      string   `json:"publicUrl"`      // URL dasar untuk link gambar, mis. di belakang reverse proxy
+	SearchTimeout  duration `json:"searchTimeout"`  // batas satu request pencarian, 0 = tanpa batas
+	MultiTimeout   duration `json:"multiTimeout"`   // batas pencarian multiple BFS
+	TaskTimeout    duration `json:"taskTimeout"`    // batas satu BFS di dalam pencarian multiple
+	Workers        int      `json:"workers"`        // worker paralel pencarian multiple BFS
+	JobTTL         duration `json:"jobTtl"`         // lama job /jobs yang sudah selesai disimpan
+}
+
+// time.Duration yang di JSON ditulis sebagai string, mis. "30s"
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = duration(parsed)
+	return nil
+}
+
+func defaultConfig() *Config {
+	return &Config{
+		Addr:           ":8080",
+		DataDir:        "../shared/data",
+		AllowedOrigins: []string{"http://localhost:3000"},
+		PublicURL:      "http://localhost:8080",
+		MultiTimeout:   duration(10 * time.Second),
+		TaskTimeout:    duration(15 * time.Second),
+		Workers:        runtime.NumCPU(),
+		JobTTL:         duration(10 * time.Minute),
+	}
+}
+
+// Satu pengaturan yang bisa diisi dari flag dan environment
+type setting struct {
+	flag  string
+	env   string
+	usage string
+	set   func(cfg *Config, value string) error
+}
+
+var settings = []setting{
+	{"addr", "ALCHEMY_ADDR", "listen address", func(cfg *Config, value string) error {
+		cfg.Addr = value
+		return nil
+	}},
+	{"data-dir", "ALCHEMY_DATA_DIR", "directory with elements.json and images/", func(cfg *Config, value string) error {
+		cfg.DataDir = value
+		return nil
+	}},
+	{"allowed-origins", "ALCHEMY_ALLOWED_ORIGINS", "comma-separated CORS origins, * for any", func(cfg *Config, value string) error {
+		cfg.AllowedOrigins = splitList(value)
+		return nil
+	}},
+	{"public-url", "ALCHEMY_PUBLIC_URL", "base URL clients use to reach this server", func(cfg *Config, value string) error {
+		cfg.PublicURL = value
+		return nil
+	}},
+	{"search-timeout", "ALCHEMY_SEARCH_TIMEOUT", "limit for one search request, 0 = none", durationSetter(func(cfg *Config) *duration { return &cfg.SearchTimeout })},
+	{"multi-timeout", "ALCHEMY_MULTI_TIMEOUT", "limit for a multiple-recipe BFS search", durationSetter(func(cfg *Config) *duration { return &cfg.MultiTimeout })},
+	{"task-timeout", "ALCHEMY_TASK_TIMEOUT", "limit for one BFS inside a multiple-recipe search", durationSetter(func(cfg *Config) *duration { return &cfg.TaskTimeout })},
+	{"job-ttl", "ALCHEMY_JOB_TTL", "how long finished /jobs results are kept", durationSetter(func(cfg *Config) *duration { return &cfg.JobTTL })},
+	{"workers", "ALCHEMY_WORKERS", "parallel workers for multiple-recipe BFS", func(cfg *Config, value string) error {
+		workers, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		cfg.Workers = workers
+		return nil
+	}},
+}
+
+func durationSetter(field func(cfg *Config) *duration) func(cfg *Config, value string) error {
+	return func(cfg *Config, value string) error {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field(cfg) = duration(parsed)
+		return nil
+	}
+}
+
+func splitList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// Baca konfigurasi dari args (tanpa nama program), environment dan file -config / ALCHEMY_CONFIG
+func loadConfig(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv("ALCHEMY_CONFIG"), "optional JSON config file (env ALCHEMY_CONFIG)")
+	flagValues := map[string]string{}
+	for _, s := range settings {
+		name := s.flag
+		fs.Func(name, s.usage+" (env "+s.env+")", func(value string) error {
+			flagValues[name] = value
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := defaultConfig()
+	if *configPath != "" {
+		data, err := os.ReadFile(*configPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("config %s: %w", *configPath, err)
+		}
+	}
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env); ok {
+			if err := s.set(cfg, value); err != nil {
+				return nil, fmt.Errorf("%s: %w", s.env, err)
+			}
+		}
+	}
+	for _, s := range settings {
+		if value, ok := flagValues[s.flag]; ok {
+			if err := s.set(cfg, value); err != nil {
+				return nil, fmt.Errorf("-%s: %w", s.flag, err)
+			}
+		}
+	}
+
+	cfg.PublicURL = strings.TrimSuffix(cfg.PublicURL, "/")
+	if cfg.Workers < 1 {
+		return nil, fmt.Errorf("workers must be at least 1, got %d", cfg.Workers)
+	}
+	if cfg.SearchTimeout < 0 || cfg.MultiTimeout <= 0 || cfg.TaskTimeout <= 0 || cfg.JobTTL <= 0 {
+		return nil, fmt.Errorf("timeouts must be positive (searchTimeout may be 0 for no limit)")
+	}
+	return cfg, nil
+}
+
+func (cfg *Config) allowsOrigin(origin string) bool {
+	for _, allowed := range cfg.AllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
@@ -1,4 +1,4 @@
-module bfs
+module server
 
 go 1.24.3
 
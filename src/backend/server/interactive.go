@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"shared/algorithm"
+	"shared/model"
+	"sync"
+)
+
+// Protokol /search/ws untuk menjalankan pencarian langkah demi langkah.
+//
+// Client mengirim:
+//
+//	{"type": "start", "request": {...SearchRequest}, "paused": true}
+//	{"type": "pause"} / {"type": "step"} / {"type": "resume"} / {"type": "cancel"}
+//
+// Server mengirim "started", satu "node" untuk setiap node yang diekspansi
+// (dengan kombinasi yang dicoba), "state" setelah pause / resume, lalu
+// "result" atau "cancelled". Kesalahan dikirim sebagai "error".
+type socketCommand struct {
+	Type    string              `json:"type"`
+	Request model.SearchRequest `json:"request"`
+	Paused  bool                `json:"paused"` // mulai dalam keadaan pause, jalan dengan "step"
+}
+
+type socketMessage struct {
+	Type   string              `json:"type"`
+	Node   *nodeEvent          `json:"node,omitempty"`
+	State  string              `json:"state,omitempty"` // "paused" atau "running"
+	Result *model.SearchResult `json:"result,omitempty"`
+	Error  string              `json:"error,omitempty"`
+}
+
+// progressEvent ditambah kombinasi yang dicoba, untuk setiap node
+type nodeEvent struct {
+	progressEvent
+	Via   *model.Recipe  `json:"via,omitempty"`
+	Tried []model.Recipe `json:"tried,omitempty"`
+}
+
+// Method yang mengikuti Controller dan melaporkan setiap node
+var steppableMethods = map[string]bool{"": true, "BFS": true, "DFS": true, "IDDFS": true}
+
+// Satu pencarian yang sedang berjalan di sebuah koneksi
+type socketSearch struct {
+	control *algorithm.Controller
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+type socketSession struct {
+	conn    *wsConn
+	ctx     context.Context
+	mu      sync.Mutex
+	current *socketSearch
+}
+
+func handleSearchSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgradeWebSocket(w, r)
+	if err != nil {
+		log.Printf("WebSocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	//Hijacked connections are not cancelled with the request, so the session owns its context
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	session := &socketSession{conn: conn, ctx: ctx}
+	defer session.stop()
+
+	for {
+		data, err := conn.ReadMessage()
+		if err != nil {
+			if !errors.Is(err, errWebSocketClosed) {
+				log.Printf("WebSocket read: %v", err)
+			}
+			return
+		}
+		var cmd socketCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			conn.WriteJSON(socketMessage{Type: "error", Error: "invalid message"})
+			continue
+		}
+		session.handle(cmd)
+	}
+}
+
+func (session *socketSession) handle(cmd socketCommand) {
+	session.mu.Lock()
+	current := session.current
+	session.mu.Unlock()
+
+	switch cmd.Type {
+	case "start":
+		session.start(cmd)
+		return
+	case "pause", "step", "resume", "cancel":
+		if current == nil {
+			session.conn.WriteJSON(socketMessage{Type: "error", Error: "no search running"})
+			return
+		}
+	default:
+		session.conn.WriteJSON(socketMessage{Type: "error", Error: "unknown message type " + cmd.Type})
+		return
+	}
+
+	switch cmd.Type {
+	case "pause":
+		current.control.Pause()
+		session.conn.WriteJSON(socketMessage{Type: "state", State: "paused"})
+	case "step":
+		current.control.Step()
+	case "resume":
+		current.control.Resume()
+		session.conn.WriteJSON(socketMessage{Type: "state", State: "running"})
+	case "cancel":
+		current.cancel()
+	}
+}
+
+func (session *socketSession) start(cmd socketCommand) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.current != nil {
+		session.conn.WriteJSON(socketMessage{Type: "error", Error: "a search is already running"})
+		return
+	}
+
+	job, err := newSearchJob(cmd.Request)
+	if err == nil && (!steppableMethods[job.strategy.Type] || len(cmd.Request.Targets) > 0) {
+		err = errors.New("step-through supports single-target BFS, DFS and IDDFS")
+	}
+	//Multiple BFS runs many searches in parallel workers, so there is no single node to step through
+	if err == nil && job.searchPaths > 1 && (job.strategy.Type == "" || job.strategy.Type == "BFS") {
+		err = errors.New("step-through BFS supports single mode only")
+	}
+	if err != nil {
+		session.conn.WriteJSON(socketMessage{Type: "error", Error: err.Error()})
+		return
+	}
+
+	control := algorithm.NewController()
+	if cmd.Paused {
+		control.Pause()
+	}
+	job.strategy.Control = control
+	ctx, cancel := context.WithCancel(session.ctx)
+	search := &socketSearch{control: control, cancel: cancel, done: make(chan struct{})}
+	session.current = search
+	session.conn.WriteJSON(socketMessage{Type: "started"})
+	if cmd.Paused {
+		session.conn.WriteJSON(socketMessage{Type: "state", State: "paused"})
+	}
+
+	step := make(chan *algorithm.SearchProgress)
+	finished := make(chan model.SearchResult, 1)
+	go func() {
+		finished <- job.run(ctx, step)
+	}()
+	go func() {
+		defer close(search.done)
+		defer cancel()
+		for {
+			select {
+			case node := <-step:
+				event := &nodeEvent{progressEvent: *newProgressEvent(node), Via: node.Via, Tried: node.Tried}
+				if err := session.conn.WriteJSON(socketMessage{Type: "node", Node: event}); err != nil {
+					cancel()
+				}
+			case result := <-finished:
+				session.mu.Lock()
+				session.current = nil
+				session.mu.Unlock()
+				if ctx.Err() != nil {
+					session.conn.WriteJSON(socketMessage{Type: "cancelled"})
+				} else {
+					session.conn.WriteJSON(socketMessage{Type: "result", Result: &result})
+				}
+				return
+			}
+		}
+	}()
+}
+
+// Hentikan pencarian yang berjalan dan tunggu sampai selesai
+func (session *socketSession) stop() {
+	session.mu.Lock()
+	current := session.current
+	session.mu.Unlock()
+	if current != nil {
+		current.cancel()
+		<-current.done
+	}
+}
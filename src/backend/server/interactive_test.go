@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"shared/algorithm"
+	"shared/utility"
+	"sync"
+	"testing"
+	"time"
+)
+
+var loadTestDataOnce sync.Once
+
+// Isi db, recipeCounts dan cfg seperti saat server start, dengan data elemen asli
+func loadTestData(t *testing.T) {
+	t.Helper()
+	loadTestDataOnce.Do(func() {
+		var err error
+		db, err = utility.LoadElementsFromFile("../shared/data/elements.json")
+		if err != nil {
+			t.Fatalf("load elements: %v", err)
+		}
+		recipeCounts = algorithm.CountRecipeTrees(db, utility.DefaultStartElements)
+	})
+	cfg = defaultConfig()
+}
+
+// Server /search/ws
+func newSocketServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	loadTestData(t)
+	server := httptest.NewServer(http.HandlerFunc(handleSearchSocket))
+	t.Cleanup(server.Close)
+	return server
+}
+
+type socketClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialSocket(t *testing.T) *socketClient {
+	conn, reader := dialWebSocket(t, newSocketServer(t))
+	return &socketClient{t: t, conn: conn, reader: reader}
+}
+
+func (c *socketClient) send(message string) {
+	c.t.Helper()
+	if _, err := c.conn.Write(clientFrame(true, wsText, []byte(message))); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *socketClient) receive() socketMessage {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	defer c.conn.SetReadDeadline(time.Time{})
+	_, payload := readServerFrame(c.t, c.reader)
+	var message socketMessage
+	if err := json.Unmarshal(payload, &message); err != nil {
+		c.t.Fatalf("invalid message %q: %v", payload, err)
+	}
+	return message
+}
+
+func (c *socketClient) expect(messageType string) socketMessage {
+	c.t.Helper()
+	message := c.receive()
+	if message.Type != messageType {
+		c.t.Fatalf("got %+v, want %q", message, messageType)
+	}
+	return message
+}
+
+// Selama pause server tidak boleh mengirim apa pun
+func (c *socketClient) expectSilence() {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	defer c.conn.SetReadDeadline(time.Time{})
+	_, err := c.reader.Peek(1)
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		c.t.Fatalf("expected no message while paused, got err %v", err)
+	}
+}
+
+func TestSocketStepThrough(t *testing.T) {
+	client := dialSocket(t)
+	client.send(`{"type": "start", "paused": true, "request": {"target": "Mud", "method": "DFS"}}`)
+	client.expect("started")
+	if state := client.expect("state"); state.State != "paused" {
+		t.Fatalf("state %q, want paused", state.State)
+	}
+	client.expectSilence()
+
+	// Akar DFS dijalankan berurutan, jadi node pertama selalu elemen awal pertama
+	client.send(`{"type": "step"}`)
+	if node := client.expect("node"); node.Node.CurrentElement != "Air" || node.Node.Visited != 1 {
+		t.Fatalf("first step visited %q (#%d), want Air (#1)", node.Node.CurrentElement, node.Node.Visited)
+	}
+	client.expectSilence()
+	client.send(`{"type": "step"}`)
+	if node := client.expect("node"); node.Node.Via == nil || node.Node.Visited != 2 {
+		t.Fatalf("second step got %+v, want the first combination made from Air", node.Node)
+	}
+	client.expectSilence()
+
+	client.send(`{"type": "resume"}`)
+	message := client.receive()
+	for message.Type == "node" {
+		message = client.receive()
+	}
+	if message.Type != "state" || message.State != "running" {
+		t.Fatalf("got %+v, want running state", message)
+	}
+	for message = client.receive(); message.Type == "node"; message = client.receive() {
+	}
+	if message.Type != "result" || len(message.Result.Recipes) != 1 {
+		t.Fatalf("got %+v, want a result with one recipe", message)
+	}
+
+	// Sesi yang sama bisa memulai pencarian baru setelah selesai
+	client.send(`{"type": "start", "request": {"target": "Mud", "method": "BFS"}}`)
+	client.expect("started")
+	for message = client.receive(); message.Type == "node"; message = client.receive() {
+	}
+	if message.Type != "result" || len(message.Result.Recipes) != 1 {
+		t.Fatalf("got %+v, want a result with one recipe", message)
+	}
+}
+
+func TestSocketCancel(t *testing.T) {
+	client := dialSocket(t)
+	client.send(`{"type": "start", "paused": true, "request": {"target": "Human", "method": "BFS"}}`)
+	client.expect("started")
+	client.expect("state")
+	client.send(`{"type": "step"}`)
+	client.expect("node")
+
+	client.send(`{"type": "start", "request": {"target": "Mud"}}`)
+	client.expect("error")
+
+	client.send(`{"type": "cancel"}`)
+	client.expect("cancelled")
+	client.send(`{"type": "step"}`)
+	if message := client.expect("error"); message.Error != "no search running" {
+		t.Fatalf("error %q after cancel", message.Error)
+	}
+}
+
+func TestSocketRejectsUnsupportedSearches(t *testing.T) {
+	client := dialSocket(t)
+	for _, message := range []string{
+		`{"type": "pause"}`,
+		`{"type": "jump"}`,
+		`not json`,
+		`{"type": "start", "request": {"target": "Mud", "method": "OPTIMAL"}}`,
+		`{"type": "start", "request": {"target": "Mud", "method": "BFS", "mode": "multiple", "maxRecipe": 3}}`,
+		`{"type": "start", "request": {"targets": ["Mud", "Steam"]}}`,
+		`{"type": "start", "request": {"startElements": ["Nothing"], "target": "Mud"}}`,
+	} {
+		client.send(message)
+		client.expect("error")
+	}
+}
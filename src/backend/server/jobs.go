@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"shared/model"
+	"sync"
+	"time"
+)
+
+// Status job pencarian asinkron
+const (
+	jobRunning   = "running"
+	jobDone      = "done"
+	jobCancelled = "cancelled"
+	jobFailed    = "failed"
+)
+
+// Pencarian yang berjalan di belakang /jobs
+type asyncJob struct {
+	id      string
+	cancel  context.CancelFunc
+	created time.Time
+
+	mu       sync.Mutex
+	status   string
+	partial  [][]model.Recipe // jalur yang sudah ditemukan selama pencarian berjalan
+	result   *model.SearchResult
+	err      string // alasan status failed
+	finished time.Time
+}
+
+type jobStatus struct {
+	ID         string              `json:"id"`
+	Status     string              `json:"status"`
+	Recipes    [][]model.Recipe    `json:"recipes"` // hasil sementara selama running, hasil akhir setelah selesai
+	Result     *model.SearchResult `json:"result,omitempty"`
+	Error      string              `json:"error,omitempty"`
+	CreatedAt  time.Time           `json:"createdAt"`
+	FinishedAt *time.Time          `json:"finishedAt,omitempty"`
+	ExpiresAt  *time.Time          `json:"expiresAt,omitempty"`
+}
+
+func (job *asyncJob) snapshot() jobStatus {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	status := jobStatus{
+		ID:        job.id,
+		Status:    job.status,
+		Recipes:   append([][]model.Recipe{}, job.partial...),
+		Result:    job.result,
+		Error:     job.err,
+		CreatedAt: job.created,
+	}
+	if !job.finished.IsZero() {
+		finished := job.finished
+		expires := finished.Add(time.Duration(cfg.JobTTL))
+		if job.result != nil {
+			status.Recipes = job.result.Recipes
+		}
+		status.FinishedAt, status.ExpiresAt = &finished, &expires
+	}
+	return status
+}
+
+type jobStore struct {
+	mu   sync.Mutex
+	jobs map[string]*asyncJob
+}
+
+var jobs = &jobStore{jobs: make(map[string]*asyncJob)}
+
+func newJobID() string {
+	var id [8]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// Jalankan pencarian di goroutine sendiri. Job yang selesai dihapus setelah cfg.JobTTL.
+func (store *jobStore) submit(search *searchJob) *asyncJob {
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &asyncJob{
+		id:      newJobID(),
+		cancel:  cancel,
+		created: time.Now(),
+		status:  jobRunning,
+		partial: [][]model.Recipe{},
+	}
+	//Multiple BFS reports every collected path, other methods only have results at the end
+	search.strategy.OnPath = func(path []model.Recipe) {
+		job.mu.Lock()
+		job.partial = append(job.partial, path)
+		job.mu.Unlock()
+	}
+
+	store.mu.Lock()
+	store.jobs[job.id] = job
+	store.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		//Job berjalan di luar handler HTTP, jadi panic di sini menjatuhkan seluruh server
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("Job %s failed: %v\n%s", job.id, r, debug.Stack())
+				job.finish(nil, fmt.Sprint(r))
+			}
+			time.AfterFunc(time.Duration(cfg.JobTTL), func() {
+				store.remove(job.id)
+			})
+		}()
+		result := search.run(ctx, nil)
+		job.finish(&result, "")
+	}()
+	return job
+}
+
+func (job *asyncJob) finish(result *model.SearchResult, failure string) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	job.result = result
+	job.finished = time.Now()
+	switch {
+	case failure != "":
+		job.status = jobFailed
+		job.err = failure
+	case job.status == jobRunning:
+		job.status = jobDone
+	}
+}
+
+func (store *jobStore) get(id string) (*asyncJob, bool) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	job, ok := store.jobs[id]
+	return job, ok
+}
+
+func (store *jobStore) remove(id string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	delete(store.jobs, id)
+}
+
+func handleSubmitJob(w http.ResponseWriter, r *http.Request) {
+	search, ok := decodeSearchJob(w, r.Body)
+	if !ok {
+		return
+	}
+	job := jobs.submit(search)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/jobs/"+job.id)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job.snapshot())
+}
+
+func handleGetJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := jobs.get(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job.snapshot())
+}
+
+// Batalkan job yang masih berjalan; hasil sementara tetap bisa diambil sampai
+// job kedaluwarsa. Job yang sudah selesai langsung dihapus.
+func handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	job, ok := jobs.get(id)
+	if !ok {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+
+	job.mu.Lock()
+	running := job.finished.IsZero()
+	if running {
+		job.status = jobCancelled
+	}
+	job.mu.Unlock()
+
+	if !running {
+		jobs.remove(id)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	job.cancel()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job.snapshot())
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"shared/algorithm"
+	"shared/model"
+	"shared/utility"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const IMAGE_DIRECTORY_SERVE_PATH = "/images/"
+
+var cfg *Config
+var db *model.ElementsDatabase
+var recipeCounts map[string]*big.Int // jumlah pohon resep dari elemen dasar, dihitung sekali
+
+type ElementInfo struct {
+	Name        string `json:"name"`
+	ImagePath   string `json:"imagePath"` // URL lengkap ke gambar
+	Tier        string `json:"tier"`
+	RecipeCount string `json:"recipeCount"` // jumlah pohon resep berbeda (bilangan besar, dalam string)
+}
+
+type ElementUse struct {
+	Element1   string `json:"element1"`
+	Element2   string `json:"element2"`
+	Result     string `json:"result"`
+	ResultTier string `json:"resultTier"`
+	ResultIcon string `json:"resultIcon"` // URL lengkap ke gambar hasil
+}
+
+func main() {
+	var err error
+	if cfg, err = loadConfig(os.Args[1:]); errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	} else if err != nil {
+		log.Fatal("Konfigurasi tidak valid: ", err)
+	}
+	algorithm.MultiSearchTimeout = time.Duration(cfg.MultiTimeout)
+	algorithm.TaskSearchTimeout = time.Duration(cfg.TaskTimeout)
+	algorithm.SearchWorkers = cfg.Workers
+
+	db, err = utility.LoadElementsFromFile(filepath.Join(cfg.DataDir, "elements.json"))
+	if err != nil || db == nil || db.Elements == nil {
+		log.Fatal("Database elemen gagal dimuat atau kosong: ", err)
+	}
+	recipeCounts = algorithm.CountRecipeTrees(db, utility.DefaultStartElements)
+
+	imageDirPath := filepath.Join(cfg.DataDir, "images")
+	http.Handle(IMAGE_DIRECTORY_SERVE_PATH,
+		http.StripPrefix(IMAGE_DIRECTORY_SERVE_PATH, http.FileServer(http.Dir(imageDirPath))))
+
+	http.HandleFunc("/search", handleSearch)
+	http.HandleFunc("/search/stream", handleSearchStream)
+	http.HandleFunc("GET /search/ws", handleSearchSocket)
+	http.HandleFunc("POST /jobs", handleSubmitJob)
+	http.HandleFunc("GET /jobs/{id}", handleGetJob)
+	http.HandleFunc("DELETE /jobs/{id}", handleCancelJob)
+	http.HandleFunc("/elements-info", handleElementsInfo)
+	http.HandleFunc("/reachability", handleReachability)
+	http.HandleFunc("/craftable", handleCraftable)
+	http.HandleFunc("GET /elements/{name}/uses", handleElementUses)
+	http.HandleFunc("GET /route", handleRoute)
+	http.HandleFunc("GET /analysis", handleAnalysis)
+
+	log.Printf("Server listening at %s (public URL %s)", cfg.Addr, cfg.PublicURL)
+	log.Fatal(http.ListenAndServe(cfg.Addr, corsMiddleware(http.DefaultServeMux)))
+}
+
+func corsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" && cfg.allowsOrigin(origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		w.Header().Add("Vary", "Origin")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// URL lengkap gambar elemen dari field Icon
+func imageURL(icon string) string {
+	if icon != "" && !strings.HasPrefix(icon, "/") { // Jika Icon adalah nama file saja
+		return cfg.PublicURL + IMAGE_DIRECTORY_SERVE_PATH + icon
+	} else if strings.HasPrefix(icon, "/") { // Jika Icon sudah punya leading slash
+		return cfg.PublicURL + icon
+	}
+	return cfg.PublicURL + IMAGE_DIRECTORY_SERVE_PATH + "placeholder.png" // Fallback
+}
+
+func handleElementsInfo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var elementsInfoList []ElementInfo
+	elementNames := make([]string, 0, len(db.Elements)) // Untuk sorting nama
+
+	for name := range db.Elements {
+		elementNames = append(elementNames, name)
+	}
+	sort.Strings(elementNames) // Urutkan nama elemen agar konsisten
+
+	for _, name := range elementNames {
+		el, ok := db.Elements[name]
+		if !ok {
+			continue // Seharusnya tidak terjadi jika iterasi dari keys db.Elements
+		}
+
+		currentTier := el.Tier // Asumsi Tier tidak ada yang null atau kosong
+
+		elementsInfoList = append(elementsInfoList, ElementInfo{
+			Name:        name,
+			ImagePath:   imageURL(el.Icon),
+			Tier:        currentTier,
+			RecipeCount: recipeCounts[name].String(),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(elementsInfoList) // Kirim array objek ElementInfo
+}
+
+func handleReachability(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req model.InventoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if len(req.StartElements) == 0 {
+		req.StartElements = utility.DefaultStartElements
+	}
+	if err := utility.ValidateStartElements(db, req.StartElements); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(algorithm.AnalyzeReachability(db, req.StartElements))
+}
+
+func handleCraftable(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req model.InventoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if len(req.StartElements) == 0 {
+		req.StartElements = utility.DefaultStartElements
+	}
+	if err := utility.ValidateStartElements(db, req.StartElements); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	craftable := algorithm.CraftableNow(db, req.StartElements)
+	for i := range craftable {
+		craftable[i].Icon = imageURL(craftable[i].Icon)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(craftable)
+}
+
+// Semua resep yang memakai elemen sebagai bahan, dari indeks db.UsedIn
+func handleElementUses(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if _, ok := db.Elements[name]; !ok {
+		http.Error(w, "unknown element "+strconv.Quote(name), http.StatusNotFound)
+		return
+	}
+
+	uses := []ElementUse{}
+	for _, recipe := range utility.UsesOf(db, name) {
+		result := db.Elements[recipe.Result]
+		uses = append(uses, ElementUse{
+			Element1:   recipe.Element1,
+			Element2:   recipe.Element2,
+			Result:     recipe.Result,
+			ResultTier: result.Tier,
+			ResultIcon: imageURL(result.Icon),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(uses)
+}
+
+// Urutan kombinasi untuk menemukan semua elemen, opsional dibatasi ?minTier=&maxTier=
+func handleRoute(w http.ResponseWriter, r *http.Request) {
+	tiers := map[string]int{"minTier": 0, "maxTier": 0}
+	for param := range tiers {
+		value := r.URL.Query().Get(param)
+		if value == "" {
+			continue
+		}
+		tier, err := strconv.Atoi(value)
+		if err != nil || tier < 0 {
+			http.Error(w, "invalid "+param, http.StatusBadRequest)
+			return
+		}
+		tiers[param] = tier
+	}
+
+	route := algorithm.PlanCompletion(r.Context(), db, utility.DefaultStartElements, tiers["minTier"], tiers["maxTier"])
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(route)
+}
+
+// Laporan masalah data resep: siklus, resep merujuk diri sendiri, bahan hilang, pelanggaran tier
+func handleAnalysis(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(utility.AnalyzeGraph(db))
+}
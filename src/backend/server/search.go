@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"shared/algorithm"
+	"shared/model"
+	"shared/utility"
+	"time"
+)
+
+// Method yang dikenali /search; kosong = BFS
+var searchMethods = map[string]bool{
+	"": true, "BFS": true, "DFS": true, "IDDFS": true, "BIDIR": true,
+	"OPTIMAL": true, "WEIGHTED": true, "ENUM": true,
+}
+
+// Batas maxRecipe untuk mode multiple; dengan sort dikali RankCandidateFactor
+const maxRecipesLimit = 100
+
+// Method yang tidak memakai excludeElements, excludeRecipes dan mustInclude,
+// juga maxTier, preferredTiers dan relaxTiers
+var unfilteredMethods = map[string]bool{"BIDIR": true, "ENUM": true, "OPTIMAL": true, "WEIGHTED": true}
+
+// Method yang memakai maxDepth
+var depthLimitedMethods = map[string]bool{"DFS": true, "IDDFS": true}
+
+// Permintaan pencarian yang sudah divalidasi, siap dijalankan
+type searchJob struct {
+	req         model.SearchRequest
+	db          *model.ElementsDatabase // db dengan urutan sesuai seed
+	strategy    *algorithm.SearchStrategy
+	costs       *algorithm.CostModel
+	maxPaths    int
+	searchPaths int // kandidat yang dicari, lebih banyak dari maxPaths jika hasil di-ranking
+	sortBy      string
+}
+
+func newSearchJob(req model.SearchRequest) (*searchJob, error) {
+	if len(req.StartElements) == 0 {
+		req.StartElements = utility.DefaultStartElements
+	}
+	if err := utility.ValidateStartElements(db, req.StartElements); err != nil {
+		return nil, err
+	}
+
+	job := &searchJob{
+		req:      req,
+		db:       db,
+		strategy: algorithm.NewSearchStrategy(req),
+		maxPaths: 1, // single = 1 recipe
+	}
+	if !searchMethods[job.strategy.Type] {
+		return nil, fmt.Errorf("unknown method %q", req.Method)
+	}
+	// Rencana beberapa target selalu dibuat dengan BFS
+	if len(req.Targets) > 0 && job.strategy.Type != "" && job.strategy.Type != "BFS" {
+		return nil, fmt.Errorf("targets are planned with BFS, method %s is not supported", job.strategy.Type)
+	}
+	if unfilteredMethods[job.strategy.Type] &&
+		(len(req.ExcludeElements) > 0 || len(req.ExcludeRecipes) > 0 || len(req.MustInclude) > 0) {
+		return nil, fmt.Errorf("method %s does not support excludeElements, excludeRecipes or mustInclude (use BFS, DFS or IDDFS)", job.strategy.Type)
+	}
+	if unfilteredMethods[job.strategy.Type] &&
+		(req.MaxTier != 0 || len(req.PreferredTiers) > 0 || req.RelaxTiers) {
+		return nil, fmt.Errorf("method %s does not support maxTier, preferredTiers or relaxTiers (use BFS, DFS or IDDFS)", job.strategy.Type)
+	}
+	if req.MaxDepth != 0 && !depthLimitedMethods[job.strategy.Type] {
+		return nil, fmt.Errorf("maxDepth is only supported by DFS and IDDFS")
+	}
+	if req.Mode == "multiple" {
+		if req.MaxRecipes < 1 || req.MaxRecipes > maxRecipesLimit {
+			return nil, fmt.Errorf("maxRecipe must be between 1 and %d, got %d", maxRecipesLimit, req.MaxRecipes)
+		}
+		job.maxPaths = req.MaxRecipes
+	}
+
+	sortBy, err := algorithm.ParseSort(req.Sort)
+	if err != nil {
+		return nil, err
+	}
+	job.sortBy = sortBy
+	// Ranking butuh kandidat lebih banyak dari jumlah yang dikembalikan
+	job.searchPaths = job.maxPaths
+	if sortBy != "" && job.maxPaths > 1 {
+		job.searchPaths = job.maxPaths * algorithm.RankCandidateFactor
+	}
+
+	if job.strategy.Type == "WEIGHTED" {
+		if job.costs, err = algorithm.NewCostModel(req); err != nil {
+			return nil, err
+		}
+	}
+
+	// Tanpa seed hasil deterministik (urut tier lalu nama); dengan seed urutan eksplorasi diacak
+	if req.Seed != nil {
+		job.db = utility.ShuffleOrder(db, *req.Seed)
+	}
+	return job, nil
+}
+
+// Jalankan method yang diminta. step boleh nil.
+func (job *searchJob) run(ctx context.Context, step chan<- *algorithm.SearchProgress) model.SearchResult {
+	req := job.req
+	if cfg.SearchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.SearchTimeout))
+		defer cancel()
+	}
+	if len(req.Targets) > 0 {
+		return job.runPlan(ctx)
+	}
+	log.Printf("Target: %s, Method: %s, Mode: %s, Max: %d\n", req.Target, req.Method, req.Mode, req.MaxRecipes)
+
+	start := time.Now()
+	var paths [][]model.Recipe
+	var visited int
+	var optimal *bool
+	var totalCost *float64
+	var trees []*model.TreeNode
+	switch job.strategy.Type {
+	case "DFS", "IDDFS":
+		res := algorithm.MultiDFS(ctx, job.db, req.StartElements, req.Target, job.searchPaths, job.strategy, step)
+		paths, visited = res.Paths, res.VisitedNodes
+	case "BIDIR":
+		res := algorithm.BidirDriver(ctx, job.db, req.StartElements, req.Target, job.searchPaths, step)
+		paths, visited = res.Paths, res.VisitedNodes
+	case "ENUM":
+		res := algorithm.EnumerateDriver(ctx, job.db, req.StartElements, req.Target, job.searchPaths)
+		paths, visited, trees = res.Paths, res.VisitedNodes, res.Trees
+	case "OPTIMAL":
+		res := algorithm.OptimalDriver(ctx, job.db, req.StartElements, req.Target, step)
+		paths, visited, optimal = res.Paths, res.VisitedNodes, &res.Optimal
+	case "WEIGHTED":
+		res := algorithm.WeightedDriver(ctx, job.db, req.StartElements, req.Target, job.costs, step)
+		paths, visited, optimal, totalCost = res.Paths, res.VisitedNodes, &res.Optimal, &res.TotalCost
+	default:
+		res := algorithm.Driver(ctx, job.db, req.StartElements, req.Target, job.searchPaths, job.strategy, step)
+		paths, visited = res.Paths, res.VisitedNodes
+	}
+	if job.sortBy != "" && len(paths) > 1 {
+		paths = algorithm.RankPaths(job.db, paths, req.Target, job.sortBy, job.maxPaths)
+		trees = nil
+	}
+	if trees == nil {
+		trees = algorithm.BuildTrees(paths, req.Target, req.StartElements)
+	}
+	elapsed := time.Since(start)
+
+	// Pastikan paths tidak nil sebelum mengirim
+	if paths == nil {
+		paths = [][]model.Recipe{} // Kirim array kosong jika nil
+	}
+	reason := ""
+	if len(paths) == 0 {
+		reason = algorithm.ExplainEmptyResult(job.db, req.StartElements, req.Target, job.strategy)
+	}
+
+	return model.SearchResult{
+		Recipes:      paths,
+		Trees:        trees,
+		ElapsedTime:  elapsed.Milliseconds(),
+		VisitedNodes: visited,
+		Optimal:      optimal,
+		TotalCost:    totalCost,
+		TotalRecipes: totalRecipes(req.StartElements, req.Target),
+		Reason:       reason,
+	}
+}
+
+// Jumlah pohon resep target. Untuk inventory bawaan dipakai recipeCounts yang
+// dihitung saat startup; inventory lain dihitung ulang.
+func totalRecipes(startElements []string, target string) string {
+	if sameElements(startElements, utility.DefaultStartElements) {
+		if count, ok := recipeCounts[target]; ok {
+			return count.String()
+		}
+		return "0"
+	}
+	return algorithm.CountRecipeTreesFor(db, startElements, target).String()
+}
+
+func sameElements(a, b []string) bool {
+	set := make(map[string]bool, len(a))
+	for _, elem := range a {
+		set[elem] = true
+	}
+	if len(set) != len(b) {
+		return false
+	}
+	for _, elem := range b {
+		if !set[elem] {
+			return false
+		}
+	}
+	return true
+}
+
+// Satu rencana gabungan untuk beberapa target
+func (job *searchJob) runPlan(ctx context.Context) model.SearchResult {
+	req := job.req
+	start := time.Now()
+	plan, err := algorithm.PlanTargets(ctx, job.db, req.StartElements, req.Targets, job.strategy)
+	if err != nil {
+		return model.SearchResult{Recipes: [][]model.Recipe{}, Trees: []*model.TreeNode{}, TotalRecipes: "0", Reason: err.Error()}
+	}
+	elapsed := time.Since(start)
+
+	trees := make([]*model.TreeNode, 0, len(plan.Targets))
+	for _, target := range plan.Targets {
+		trees = append(trees, algorithm.BuildTree(plan.Steps, target, req.StartElements))
+	}
+	reason := ""
+	if len(plan.Missing) > 0 {
+		reason = algorithm.ExplainEmptyResult(job.db, req.StartElements, plan.Missing[0], job.strategy)
+	}
+
+	return model.SearchResult{
+		Recipes:      [][]model.Recipe{plan.Steps},
+		Trees:        trees,
+		ElapsedTime:  elapsed.Milliseconds(),
+		VisitedNodes: plan.VisitedNodes,
+		TotalRecipes: "0",
+		Reason:       reason,
+		Plan: &model.PlanSummary{
+			Targets:       plan.Targets,
+			CombinedSteps: len(plan.Steps),
+			SeparateSteps: plan.SeparateSteps,
+			StepsSaved:    plan.StepsSaved,
+			Missing:       plan.Missing,
+		},
+	}
+}
+
+// Decode dan validasi SearchRequest dalam JSON
+func decodeSearchJob(w http.ResponseWriter, body io.Reader) (*searchJob, bool) {
+	var req model.SearchRequest
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return nil, false
+	}
+	job, err := newSearchJob(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return job, true
+}
+
+func handleSearch(w http.ResponseWriter, r *http.Request) {
+	// CORS sudah ditangani oleh middleware
+	if r.Method != http.MethodPost { // Method POST untuk search
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	job, ok := decodeSearchJob(w, r.Body)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job.run(r.Context(), nil))
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"shared/model"
+	"strings"
+	"testing"
+)
+
+// Opsi yang tidak dipakai method yang dipilih ditolak, bukan diabaikan diam-diam
+func TestNewSearchJobRejectsIgnoredOptions(t *testing.T) {
+	loadTestData(t)
+	tests := []struct {
+		name string
+		req  model.SearchRequest
+		err  string // kosong = valid
+	}{
+		{"BFS with exclusions", model.SearchRequest{Target: "Human", ExcludeElements: []string{"Mud"}}, ""},
+		{"OPTIMAL with exclusions", model.SearchRequest{Target: "Human", Method: "OPTIMAL", ExcludeElements: []string{"Mud"}}, "does not support excludeElements"},
+		{"ENUM with mustInclude", model.SearchRequest{Target: "Human", Method: "ENUM", MustInclude: []string{"Mud"}}, "does not support excludeElements"},
+		{"BFS with maxTier", model.SearchRequest{Target: "Human", MaxTier: 3}, ""},
+		{"OPTIMAL with maxTier", model.SearchRequest{Target: "Human", Method: "OPTIMAL", MaxTier: 3}, "does not support maxTier"},
+		{"BIDIR with relaxTiers", model.SearchRequest{Target: "Human", Method: "BIDIR", RelaxTiers: true}, "does not support maxTier"},
+		{"WEIGHTED with preferredTiers", model.SearchRequest{Target: "Human", Method: "WEIGHTED", PreferredTiers: []int{2}}, "does not support maxTier"},
+		{"DFS with maxDepth", model.SearchRequest{Target: "Human", Method: "DFS", MaxDepth: 3}, ""},
+		{"IDDFS with maxDepth", model.SearchRequest{Target: "Human", Method: "IDDFS", MaxDepth: 3}, ""},
+		{"BFS with maxDepth", model.SearchRequest{Target: "Human", MaxDepth: 3}, "maxDepth is only supported"},
+		{"ENUM with maxDepth", model.SearchRequest{Target: "Human", Method: "ENUM", MaxDepth: 3}, "maxDepth is only supported"},
+		{"plan with BFS", model.SearchRequest{Targets: []string{"Human", "Bread"}, Method: "BFS", ExcludeElements: []string{"Mud"}}, ""},
+		{"plan with OPTIMAL", model.SearchRequest{Targets: []string{"Human", "Bread"}, Method: "OPTIMAL"}, "targets are planned with BFS"},
+		{"plan with DFS", model.SearchRequest{Targets: []string{"Human", "Bread"}, Method: "DFS"}, "targets are planned with BFS"},
+		{"multiple without maxRecipe", model.SearchRequest{Target: "Human", Mode: "multiple"}, "maxRecipe must be between"},
+	}
+	for _, tt := range tests {
+		_, err := newSearchJob(tt.req)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: got error %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"shared/algorithm"
+	"shared/model"
+	"strings"
+	"time"
+)
+
+// Jarak minimal antar event progress; event di antaranya digabung jadi yang terbaru
+const streamInterval = 50 * time.Millisecond
+
+// Salinan SearchProgress untuk dikirim ke client. VisitedNodes sengaja tidak
+// ikut karena map itu masih diubah oleh pencarian yang sedang berjalan.
+type progressEvent struct {
+	CurrentElement string `json:"currentElement"`
+	Visited        int    `json:"visited"`
+	PathsFound     int    `json:"pathsFound"`
+	Frontier       int    `json:"frontier"`
+	Depth          int    `json:"depth"`
+}
+
+func newProgressEvent(p *algorithm.SearchProgress) *progressEvent {
+	return &progressEvent{
+		CurrentElement: p.CurrentElement,
+		Visited:        p.Visited,
+		PathsFound:     p.PathsFound,
+		Frontier:       p.Frontier,
+		Depth:          p.Depth,
+	}
+}
+
+// Jalankan pencarian dan kirim progress sebagai Server-Sent Events, diakhiri
+// satu event "result" berisi SearchResult. POST memakai body yang sama dengan
+// /search; GET (untuk EventSource) membaca JSON yang sama dari ?request=
+func handleSearchStream(w http.ResponseWriter, r *http.Request) {
+	var body io.Reader
+	switch r.Method {
+	case http.MethodPost:
+		body = r.Body
+	case http.MethodGet:
+		body = strings.NewReader(r.URL.Query().Get("request"))
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	job, ok := decodeSearchJob(w, body)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx := r.Context()
+	step := make(chan *algorithm.SearchProgress, 64)
+	done := make(chan model.SearchResult, 1)
+	go func() {
+		done <- job.run(ctx, step)
+	}()
+
+	ticker := time.NewTicker(streamInterval)
+	defer ticker.Stop()
+	var latest *progressEvent
+	for {
+		select {
+		case p := <-step:
+			latest = newProgressEvent(p)
+
+		case <-ticker.C:
+			if latest != nil {
+				writeEvent(w, "progress", latest)
+				flusher.Flush()
+				latest = nil
+			}
+
+		case result := <-done:
+			if latest != nil {
+				writeEvent(w, "progress", latest)
+			}
+			writeEvent(w, "result", result)
+			flusher.Flush()
+			return
+		}
+	}
+}
+
+func writeEvent(w io.Writer, event string, data any) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		payload, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Server yang mengembalikan setiap pesan apa adanya
+func newEchoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	cfg = defaultConfig()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgradeWebSocket(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.writeFrame(wsText, message)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// Handshake dengan contoh key dari RFC 6455 bagian 1.3
+func dialWebSocket(t *testing.T, server *httptest.Server) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	io.WriteString(conn, "GET / HTTP/1.1\r\n"+
+		"Host: example\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: keep-alive, Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n")
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status %d, want 101", response.StatusCode)
+	}
+	if accept := response.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Sec-WebSocket-Accept = %q", accept)
+	}
+	return conn, reader
+}
+
+// Frame dari client, selalu di-mask
+func clientFrame(fin bool, opcode byte, payload []byte) []byte {
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first}
+	switch {
+	case len(payload) < 126:
+		frame = append(frame, 0x80|byte(len(payload)))
+	case len(payload) <= 0xFFFF:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	default:
+		frame = append(frame, 0x80|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(len(payload)))
+	}
+	mask := [4]byte{0x37, 0xfa, 0x21, 0x3d}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	return frame
+}
+
+func readServerFrame(t *testing.T, reader *bufio.Reader) (opcode byte, payload []byte) {
+	t.Helper()
+	var header [2]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		t.Fatal(err)
+	}
+	if header[0]&0x80 == 0 || header[1]&0x80 != 0 {
+		t.Fatalf("server frame must be final and unmasked, header %x", header)
+	}
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(reader, ext[:])
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(reader, ext[:])
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		t.Fatal(err)
+	}
+	return header[0] & 0x0F, payload
+}
+
+func TestWebSocketRoundTrip(t *testing.T) {
+	conn, reader := dialWebSocket(t, newEchoServer(t))
+
+	// Pesan terpecah dengan ping di tengahnya: pong dikirim dulu, lalu pesan utuh
+	conn.Write(clientFrame(false, wsText, []byte("Hel")))
+	conn.Write(clientFrame(true, wsPing, []byte("ping")))
+	conn.Write(clientFrame(true, wsContinuation, []byte("lo")))
+	if opcode, payload := readServerFrame(t, reader); opcode != wsPong || string(payload) != "ping" {
+		t.Fatalf("got opcode %d %q, want pong \"ping\"", opcode, payload)
+	}
+	if opcode, payload := readServerFrame(t, reader); opcode != wsText || string(payload) != "Hello" {
+		t.Fatalf("got opcode %d %q, want text \"Hello\"", opcode, payload)
+	}
+
+	// Panjang 16 bit dan 64 bit
+	for _, size := range []int{300, 70000} {
+		message := bytes.Repeat([]byte("a"), size)
+		conn.Write(clientFrame(true, wsBinary, message))
+		if opcode, payload := readServerFrame(t, reader); opcode != wsText || !bytes.Equal(payload, message) {
+			t.Fatalf("size %d: got opcode %d with %d bytes", size, opcode, len(payload))
+		}
+	}
+
+	conn.Write(clientFrame(true, wsClose, nil))
+	if opcode, _ := readServerFrame(t, reader); opcode != wsClose {
+		t.Fatalf("got opcode %d, want close", opcode)
+	}
+}
+
+func TestWebSocketRejectsUnmaskedFrame(t *testing.T) {
+	conn, reader := dialWebSocket(t, newEchoServer(t))
+	conn.Write([]byte{0x80 | wsText, 2, 'h', 'i'})
+	// Server menutup koneksi tanpa membalas pesan
+	if opcode, _ := readServerFrame(t, reader); opcode != wsClose {
+		t.Fatalf("got opcode %d, want close", opcode)
+	}
+	if _, err := reader.ReadByte(); err != io.EOF {
+		t.Fatalf("connection still open: %v", err)
+	}
+}
+
+func TestWebSocketUpgradeErrors(t *testing.T) {
+	server := newEchoServer(t)
+	tests := []struct {
+		name    string
+		headers map[string]string
+		status  int
+	}{
+		{"plain request", map[string]string{}, http.StatusUpgradeRequired},
+		{"old version", map[string]string{"Sec-WebSocket-Version": "8", "Sec-WebSocket-Key": "x"}, http.StatusBadRequest},
+		{"missing key", map[string]string{"Sec-WebSocket-Version": "13"}, http.StatusBadRequest},
+		{"foreign origin", map[string]string{"Sec-WebSocket-Version": "13", "Sec-WebSocket-Key": "x", "Origin": "http://evil.example"}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		request, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		if len(tt.headers) > 0 {
+			request.Header.Set("Connection", "Upgrade")
+			request.Header.Set("Upgrade", "websocket")
+		}
+		for name, value := range tt.headers {
+			request.Header.Set(name, value)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		if response.StatusCode != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, response.StatusCode, tt.status)
+		}
+	}
+}
@@ -8,17 +8,34 @@ import (
 	"runtime"
 	"shared/model"
 	"shared/utility"
+	"sort"
 	"sync"
 	"time"
 )
 
 type SearchStrategy struct {
 	Type             string
+	Target           string // target permintaan, dikecualikan dari MaxTier
 	Exclusions       map[string]bool
 	PreferredTiers   []int
 	ShuffledElements []string
+	ExcludedRecipes  map[string]bool           // "a+b->hasil", hasil kosong = semua hasil pasangan itu
+	MustInclude      []string                  // elemen yang wajib muncul di resep
+	MaxDepth         int                       // batas kedalaman DFS / IDDFS, 0 = tanpa batas
+	MaxTier          int                       // tier tertinggi untuk elemen perantara, 0 = tanpa batas
+	RelaxTiers       bool                      // true = bahan tidak harus ber-tier lebih rendah dari hasil
+	Control          *Controller               // pause / resume / step, nil = jalan terus
+	OnPath           func(path []model.Recipe) // dipanggil tiap jalur baru yang dikumpulkan BFSMultipleThreaded, harus cepat
 }
 
+// Batas pencarian multiple BFS, boleh diganti server saat startup sebelum
+// pencarian pertama.
+var (
+	MultiSearchTimeout = 10 * time.Second // batas seluruh pencarian multiple
+	TaskSearchTimeout  = 15 * time.Second // batas satu BFS per urutan inventory
+	SearchWorkers      = runtime.NumCPU() // jumlah worker paralel
+)
+
 type BFSResult struct {
 	TargetElement string           `json:"target_element"`
 	Paths         [][]model.Recipe `json:"recipes"`
@@ -36,10 +53,24 @@ type SearchProgress struct {
 	Visited        int             `json:"visited"`
 	PathsFound     int             `json:"pathsFound"`
 	VisitedNodes   map[string]bool `json:"visitedNodes"`
+	Frontier       int             `json:"frontier"` // ukuran queue (BFS) atau stack (DFS)
+	Depth          int             `json:"depth"`
+	Via            *model.Recipe   `json:"via,omitempty"`   // kombinasi yang menghasilkan node ini
+	Tried          []model.Recipe  `json:"tried,omitempty"` // kombinasi baru yang dicoba sejak event sebelumnya
+}
+
+// Last step of a path, nil for a starting element
+func lastRecipe(path []model.Recipe) *model.Recipe {
+	if len(path) == 0 {
+		return nil
+	}
+	recipe := path[len(path)-1]
+	return &recipe
 }
 
 // Iteratively run BFS to search for a path to a missing element.
-func iterativeExpansion(path []model.Recipe, db *model.ElementsDatabase, startElements []string, step chan<- *SearchProgress) []model.Recipe {
+func iterativeExpansion(ctx context.Context, path []model.Recipe, db *model.ElementsDatabase, startElements []string,
+	strategy *SearchStrategy, step chan<- *SearchProgress) []model.Recipe {
 	workingPath := make([]model.Recipe, len(path))
 	copy(workingPath, path)
 
@@ -52,6 +83,10 @@ func iterativeExpansion(path []model.Recipe, db *model.ElementsDatabase, startEl
 	iteration := 0
 	//Find a single missing element in the path from bottom up
 	for {
+		if ctx.Err() != nil {
+			log.Printf("Expansion cancelled: %v", ctx.Err())
+			break
+		}
 		iteration++
 		log.Printf("Expansion iteration %d", iteration)
 		missingElement := ""
@@ -89,7 +124,7 @@ func iterativeExpansion(path []model.Recipe, db *model.ElementsDatabase, startEl
 		availableElements := keysFromMap(createdElements)
 
 		//Run BFS to find the path to the missing element
-		go BFSWithOptions(db, availableElements, missingElement, []int{}, 1, subResult, nil)
+		go BFSWithOptions(ctx, db, availableElements, missingElement, []int{}, 1, strategy.forSubSearch(), subResult, nil)
 
 		bfsResult := <-subResult
 		if len(bfsResult.Paths) == 0 {
@@ -122,18 +157,19 @@ func iterativeExpansion(path []model.Recipe, db *model.ElementsDatabase, startEl
 	return workingPath
 }
 
-// Helper to get keys from a map
+// Helper to get keys from a map, sorted so callers see a stable order
 func keysFromMap(m map[string]bool) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
 		keys = append(keys, k)
 	}
+	sort.Strings(keys)
 	return keys
 }
 
 // Bare BFS function for both single and multi-threaded
-func BFSWithOptions(db *model.ElementsDatabase, startElements []string, targetElement string,
-	bannedTargetRecipes []int, maxPaths int, result chan<- *BFSResult, progress chan<- *SearchProgress) {
+func BFSWithOptions(ctx context.Context, db *model.ElementsDatabase, startElements []string, targetElement string,
+	bannedTargetRecipes []int, maxPaths int, strategy *SearchStrategy, result chan<- *BFSResult, progress chan<- *SearchProgress) {
 
 	resultSent := false
 	defer func() {
@@ -148,7 +184,7 @@ func BFSWithOptions(db *model.ElementsDatabase, startElements []string, targetEl
 	}()
 
 	target, exists := db.Elements[targetElement]
-	if !exists || target.IsBasic {
+	if !exists || target.IsBasic || inInventory(startElements, targetElement) || !strategy.allowsElement(targetElement) {
 		result <- &BFSResult{
 			TargetElement: targetElement,
 			Paths:         [][]model.Recipe{},
@@ -176,17 +212,33 @@ func BFSWithOptions(db *model.ElementsDatabase, startElements []string, targetEl
 	}
 
 	discoveredElements := make(map[string]bool)
+	discoveredOrder := []string{} //same elements in discovery order, for stable iteration
 	queue := list.New()
 
+	//A seeded database decides the order of the start elements and of new nodes
+	var seededRank map[string]int
+	owned := strategy.ownedElements(startElements)
+	if db.Shuffled {
+		seededRank = make(map[string]int, len(db.Order))
+		for i, name := range db.Order {
+			seededRank[name] = i
+		}
+		owned = append([]string{}, owned...)
+		sort.SliceStable(owned, func(i, j int) bool { return seededRank[owned[i]] < seededRank[owned[j]] })
+	}
+
 	//Initialize queue with start elements
-	for _, basic := range startElements {
+	for _, basic := range owned {
 		node := &BFSNode{
 			Element:    basic,
 			Path:       []model.Recipe{},
 			ParentNode: nil,
 		}
 		queue.PushBack(node)
-		discoveredElements[basic] = true
+		if !discoveredElements[basic] {
+			discoveredElements[basic] = true
+			discoveredOrder = append(discoveredOrder, basic)
+		}
 	}
 
 	paths := [][]model.Recipe{}
@@ -194,22 +246,38 @@ func BFSWithOptions(db *model.ElementsDatabase, startElements []string, targetEl
 
 	//BFS main loop
 	for queue.Len() > 0 && (maxPaths <= 0 || len(paths) < maxPaths) {
+		if err := strategy.controller().Wait(ctx); err != nil {
+			log.Printf("BFS for %s cancelled: %v", targetElement, err)
+			break
+		}
 		visitedCount++
 		node := queue.Remove(queue.Front()).(*BFSNode)
-
-		if progress != nil {
-			select {
-			case progress <- &SearchProgress{
+		report := func(tried []model.Recipe) {
+			if progress == nil {
+				return
+			}
+			strategy.controller().send(ctx, progress, &SearchProgress{
 				CurrentElement: node.Element,
 				Visited:        visitedCount,
 				PathsFound:     len(paths),
 				VisitedNodes:   discoveredElements,
-			}:
-			default:
-			}
+				Frontier:       queue.Len(),
+				Depth:          len(node.Path),
+				Via:            lastRecipe(node.Path),
+				Tried:          tried,
+			})
 		}
 
 		if node.Element == targetElement {
+			report(nil)
+			if strategy.hasRequirements() {
+				//Only the expanded path shows every element the recipe really uses
+				expanded := iterativeExpansion(ctx, node.Path, db, startElements, strategy, progress)
+				if !strategy.includesRequired(expanded) {
+					continue
+				}
+				node.Path = expanded
+			}
 			paths = append(paths, node.Path)
 			if maxPaths > 0 && len(paths) >= maxPaths {
 				break
@@ -218,14 +286,16 @@ func BFSWithOptions(db *model.ElementsDatabase, startElements []string, targetEl
 		}
 
 		//Try combinations with discovered elements
-		for otherElementID := range discoveredElements {
+		candidates := []model.Recipe{}
+		for _, otherElementID := range discoveredOrder {
 			e1, e2 := node.Element, otherElementID
 			if e1 > e2 {
 				e1, e2 = e2, e1
 			}
 
 			//Check all possible results for this combination
-			for resultElementName, resultElement := range db.Elements {
+			for _, recipe := range utility.CombinationResults(db, e1, e2) {
+				resultElementName := recipe.Result
 				if discoveredElements[resultElementName] && resultElementName != targetElement {
 					continue
 				}
@@ -237,47 +307,45 @@ func BFSWithOptions(db *model.ElementsDatabase, startElements []string, targetEl
 					continue
 				}
 
-				for _, recipe := range resultElement.Recipes {
-					if (recipe.Element1 == e1 && recipe.Element2 == e2) ||
-						(recipe.Element1 == e2 && recipe.Element2 == e1) {
-
-						if !isValidTierProgression(recipe, resultElement, db) {
-							continue
-						}
-						//Mark this specific combination->result as visited
-						visitedCombinations[combinationKey] = true
-
-						//Create Recipe with result
-						recipeWithResult := model.Recipe{
-							Element1: recipe.Element1,
-							Element2: recipe.Element2,
-							Result:   resultElementName,
-						}
-
-						newPath := make([]model.Recipe, len(node.Path)+1)
-						copy(newPath, node.Path)
-						newPath[len(node.Path)] = recipeWithResult
-
-						newNode := &BFSNode{
-							Element:    resultElementName,
-							Path:       newPath,
-							ParentNode: node,
-						}
-						queue.PushBack(newNode)
+				resultElement, ok := db.Elements[resultElementName]
+				if !ok || !strategy.allowsRecipe(recipe) || !strategy.allowsTier(recipe, resultElement, db) {
+					continue
+				}
+				//Mark this specific combination->result as visited
+				visitedCombinations[combinationKey] = true
+				candidates = append(candidates, recipe)
 
-						if !discoveredElements[resultElementName] {
-							discoveredElements[resultElementName] = true
-						}
-						break
-					}
+				if !discoveredElements[resultElementName] {
+					discoveredElements[resultElementName] = true
+					discoveredOrder = append(discoveredOrder, resultElementName)
 				}
 			}
 		}
+
+		if seededRank != nil {
+			sort.SliceStable(candidates, func(i, j int) bool {
+				return seededRank[candidates[i].Result] < seededRank[candidates[j].Result]
+			})
+		}
+		//Preferred tiers are queued first
+		candidates = strategy.preferredFirst(candidates, db)
+		for _, recipe := range candidates {
+			newPath := make([]model.Recipe, len(node.Path)+1)
+			copy(newPath, node.Path)
+			newPath[len(node.Path)] = recipe
+
+			queue.PushBack(&BFSNode{
+				Element:    recipe.Result,
+				Path:       newPath,
+				ParentNode: node,
+			})
+		}
+		report(candidates)
 	}
 
 	//Apply iterative expansion to all paths
 	for i := range paths {
-		paths[i] = iterativeExpansion(paths[i], db, startElements, progress)
+		paths[i] = iterativeExpansion(ctx, paths[i], db, startElements, strategy, progress)
 	}
 
 	result <- &BFSResult{
@@ -288,45 +356,101 @@ func BFSWithOptions(db *model.ElementsDatabase, startElements []string, targetEl
 	resultSent = true
 }
 
-func BFSSingle(db *model.ElementsDatabase, startElements []string, targetElement string,
-	result chan<- *BFSResult, progress chan<- *SearchProgress) {
-	BFSWithOptions(db, startElements, targetElement, []int{}, 1, result, progress)
+func BFSSingle(ctx context.Context, db *model.ElementsDatabase, startElements []string, targetElement string,
+	strategy *SearchStrategy, result chan<- *BFSResult, progress chan<- *SearchProgress) {
+	BFSWithOptions(ctx, db, startElements, targetElement, []int{}, 1, strategy, result, progress)
 }
 
-func BFSMultipleThreaded(db *model.ElementsDatabase, startElements []string,
+func BFSMultipleThreaded(parent context.Context, db *model.ElementsDatabase, startElements []string,
 	//Init
-	targetElement string, maxPaths int, timeoutSeconds int,
-	result chan<- *BFSResult) {
-	numWorkers := runtime.NumCPU()
-	pathsChan := make(chan []model.Recipe, maxPaths*2)
+	targetElement string, maxPaths int, timeout time.Duration,
+	strategy *SearchStrategy, result chan<- *BFSResult, progress chan<- *SearchProgress) {
+	numWorkers := max(SearchWorkers, 1)
+	pathsChan := make(chan taskPath, maxPaths*2)
 	tasks := make(chan BFSTask, 100)
 	done := make(chan bool, 1)
 	var mu sync.Mutex
 	collectedPaths := make([][]model.Recipe, 0, maxPaths)
+	collectedKeys := make(map[string]bool)
 	totalVisited := 0
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSeconds)*time.Second)
+	//Progress is only a hint here, so a slow reader never holds up the workers
+	report := func(p *SearchProgress) {
+		if progress == nil {
+			return
+		}
+		select {
+		case progress <- p:
+		default:
+		}
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
 	defer cancel()
 	//Threading Mumbo Jumbo
+	//Results are only taken in task order, so the same request always
+	//collects the same paths no matter which worker finishes first
+	finished := make(map[int]taskPath) // tasks done before an earlier one, guarded by mu
+	buffer := func(found taskPath) {
+		if found.Path != nil {
+			found.key = pathTreeKey(found.Path, targetElement, startElements)
+		}
+		finished[found.Index] = found
+	}
+	//Paths found so far, including those still waiting for an earlier task
+	foundPaths := func() int {
+		waiting := make(map[string]bool)
+		for _, found := range finished {
+			if found.Path != nil && !collectedKeys[found.key] {
+				waiting[found.key] = true
+			}
+		}
+		return len(collectedPaths) + len(waiting)
+	}
+	collect := func(current taskPath) {
+		totalVisited += current.Visited
+		if current.Path == nil || len(collectedPaths) >= maxPaths || collectedKeys[current.key] {
+			return
+		}
+		collectedKeys[current.key] = true
+		collectedPaths = append(collectedPaths, current.Path)
+		log.Printf("Found path %d/%d with %d steps", len(collectedPaths), maxPaths, len(current.Path))
+		strategy.pathFound(current.Path)
+		report(&SearchProgress{
+			CurrentElement: targetElement,
+			Visited:        totalVisited,
+			PathsFound:     len(collectedPaths),
+		})
+	}
+	//Workers deliver every task they finish, so the collector reads until the channel closes
 	go func() {
-		for {
-			select {
-			case path, ok := <-pathsChan:
-				if !ok {
-					done <- true
-					return
-				}
-				mu.Lock()
-				if len(collectedPaths) < maxPaths && !isDuplicatePath(path, collectedPaths) {
-					collectedPaths = append(collectedPaths, path)
-					log.Printf("Found path %d/%d with %d steps", len(collectedPaths), maxPaths, len(path))
+		nextTask := 0
+		for found := range pathsChan {
+			mu.Lock()
+			buffer(found)
+			for {
+				current, ready := finished[nextTask]
+				if !ready {
+					break
 				}
-				mu.Unlock()
-
-			case <-ctx.Done():
-				done <- true
-				return
+				delete(finished, nextTask)
+				nextTask++
+				collect(current)
 			}
+			mu.Unlock()
+		}
+
+		//Tasks cut off by the timeout leave gaps; what finished after them still counts, in task order
+		mu.Lock()
+		indexes := make([]int, 0, len(finished))
+		for index := range finished {
+			indexes = append(indexes, index)
 		}
+		sort.Ints(indexes)
+		for _, index := range indexes {
+			collect(finished[index])
+			delete(finished, index)
+		}
+		mu.Unlock()
+		done <- true
 	}()
 
 	var wg sync.WaitGroup
@@ -335,9 +459,26 @@ func BFSMultipleThreaded(db *model.ElementsDatabase, startElements []string,
 		go func(workerID int) {
 			defer wg.Done()
 
+			//Worker progress counts paths collected so far instead of the single BFS's own
 			progressChan := make(chan *SearchProgress, 100)
+			drained := make(chan struct{})
+			defer func() {
+				close(progressChan)
+				<-drained
+			}()
 			go func() {
-				for range progressChan {
+				defer close(drained)
+				for p := range progressChan {
+					mu.Lock()
+					found := len(collectedPaths)
+					mu.Unlock()
+					report(&SearchProgress{
+						CurrentElement: p.CurrentElement,
+						Visited:        p.Visited,
+						PathsFound:     found,
+						Frontier:       p.Frontier,
+						Depth:          p.Depth,
+					})
 				}
 			}()
 
@@ -345,12 +486,11 @@ func BFSMultipleThreaded(db *model.ElementsDatabase, startElements []string,
 				select {
 				case task, ok := <-tasks:
 					if !ok {
-						close(progressChan)
 						return
 					}
 
 					mu.Lock()
-					shouldStop := len(collectedPaths) >= maxPaths
+					shouldStop := foundPaths() >= maxPaths
 					mu.Unlock()
 
 					if shouldStop {
@@ -360,40 +500,31 @@ func BFSMultipleThreaded(db *model.ElementsDatabase, startElements []string,
 					log.Printf("Worker %d: Processing task with shuffle %v, banned %v",
 						workerID, task.Shuffle, task.BannedRecipes)
 
-					searchCtx, searchCancel := context.WithTimeout(ctx, 15*time.Second)
+					searchCtx, searchCancel := context.WithTimeout(ctx, TaskSearchTimeout)
 					resultChan := make(chan *BFSResult, 1)
 
-					go BFSWithOptions(db, task.Shuffle, targetElement, task.BannedRecipes,
-						1, resultChan, progressChan)
+					go BFSWithOptions(searchCtx, db, task.Shuffle, targetElement, task.BannedRecipes,
+						1, strategy, resultChan, progressChan)
 
+					found := taskPath{Index: task.Index}
 					select {
 					case bfsResult := <-resultChan:
 						searchCancel()
-
-						mu.Lock()
-						totalVisited += bfsResult.VisitedNodes
-						mu.Unlock()
-
+						found.Visited = bfsResult.VisitedNodes
 						if len(bfsResult.Paths) > 0 {
-							select {
-							case pathsChan <- bfsResult.Paths[0]:
-							case <-ctx.Done():
-								return
-							}
+							found.Path = bfsResult.Paths[0]
 						}
 
 					case <-searchCtx.Done():
 						searchCancel()
+						//Wait for the cancelled BFS so it stops using progressChan
+						<-resultChan
 						log.Printf("Worker %d: Search timeout", workerID)
-
-					case <-ctx.Done():
-						searchCancel()
-						close(progressChan)
-						return
 					}
 
+					pathsChan <- found
+
 				case <-ctx.Done():
-					close(progressChan)
 					return
 				}
 			}
@@ -411,9 +542,10 @@ func BFSMultipleThreaded(db *model.ElementsDatabase, startElements []string,
 			targetRecipeCount = len(targetElem.Recipes)
 		}
 
+		nextIndex := 0
 		for _, shuffle := range shuffles {
 			mu.Lock()
-			currentCount := len(collectedPaths)
+			currentCount := foundPaths()
 			mu.Unlock()
 
 			if currentCount >= maxPaths {
@@ -421,9 +553,11 @@ func BFSMultipleThreaded(db *model.ElementsDatabase, startElements []string,
 			}
 
 			task := BFSTask{
+				Index:         nextIndex,
 				Shuffle:       shuffle,
 				BannedRecipes: []int{},
 			}
+			nextIndex++
 
 			select {
 			case tasks <- task:
@@ -432,12 +566,16 @@ func BFSMultipleThreaded(db *model.ElementsDatabase, startElements []string,
 			}
 		}
 
-		time.Sleep(500 * time.Millisecond)
+		select {
+		case <-time.After(500 * time.Millisecond):
+		case <-ctx.Done():
+			return
+		}
 
 		bannedRecipes := []int{}
 		for recipeIdx := 0; recipeIdx < targetRecipeCount; recipeIdx++ {
 			mu.Lock()
-			currentCount := len(collectedPaths)
+			currentCount := foundPaths()
 			mu.Unlock()
 
 			if currentCount >= maxPaths {
@@ -448,9 +586,11 @@ func BFSMultipleThreaded(db *model.ElementsDatabase, startElements []string,
 			log.Printf("Starting phase 2: Banning recipe %d", recipeIdx)
 
 			task := BFSTask{
+				Index:         nextIndex,
 				Shuffle:       startElements,
 				BannedRecipes: append([]int{}, bannedRecipes...),
 			}
+			nextIndex++
 
 			select {
 			case tasks <- task:
@@ -459,7 +599,7 @@ func BFSMultipleThreaded(db *model.ElementsDatabase, startElements []string,
 			}
 			for j := 0; j < min(3, len(shuffles)); j++ {
 				mu.Lock()
-				currentCount := len(collectedPaths)
+				currentCount := foundPaths()
 				mu.Unlock()
 
 				if currentCount >= maxPaths {
@@ -467,9 +607,11 @@ func BFSMultipleThreaded(db *model.ElementsDatabase, startElements []string,
 				}
 
 				task := BFSTask{
+					Index:         nextIndex,
 					Shuffle:       shuffles[j],
 					BannedRecipes: append([]int{}, bannedRecipes...),
 				}
+				nextIndex++
 
 				select {
 				case tasks <- task:
@@ -502,43 +644,56 @@ func BFSMultipleThreaded(db *model.ElementsDatabase, startElements []string,
 	close(result)
 }
 
+// Outcome of one BFSTask, Path is nil when the task found nothing
+type taskPath struct {
+	Index   int
+	Path    []model.Recipe
+	Visited int
+	key     string // pathTreeKey of Path, set by the collector
+}
+
 type BFSTask struct {
+	Index         int
 	Shuffle       []string
 	BannedRecipes []int
 }
 
+// Maximum number of inventory orderings tried by BFSMultipleThreaded
+const maxShuffles = 24
+
+// Orderings of the starting inventory, at most maxShuffles of them (all 24 for
+// the usual four basic elements).
 func generateFixedShuffles(elements []string) [][]string {
-	if len(elements) != 4 {
+	if len(elements) <= 1 {
 		return [][]string{elements}
 	}
 
 	perms := [][]string{}
-	a, b, c, d := elements[0], elements[1], elements[2], elements[3]
-
-	perms = append(perms, []string{a, b, c, d})
-	perms = append(perms, []string{a, b, d, c})
-	perms = append(perms, []string{a, c, b, d})
-	perms = append(perms, []string{a, c, d, b})
-	perms = append(perms, []string{a, d, b, c})
-	perms = append(perms, []string{a, d, c, b})
-	perms = append(perms, []string{b, a, c, d})
-	perms = append(perms, []string{b, a, d, c})
-	perms = append(perms, []string{b, c, a, d})
-	perms = append(perms, []string{b, c, d, a})
-	perms = append(perms, []string{b, d, a, c})
-	perms = append(perms, []string{b, d, c, a})
-	perms = append(perms, []string{c, a, b, d})
-	perms = append(perms, []string{c, a, d, b})
-	perms = append(perms, []string{c, b, a, d})
-	perms = append(perms, []string{c, b, d, a})
-	perms = append(perms, []string{c, d, a, b})
-	perms = append(perms, []string{c, d, b, a})
-	perms = append(perms, []string{d, a, b, c})
-	perms = append(perms, []string{d, a, c, b})
-	perms = append(perms, []string{d, b, a, c})
-	perms = append(perms, []string{d, b, c, a})
-	perms = append(perms, []string{d, c, a, b})
-	perms = append(perms, []string{d, c, b, a})
+	current := make([]string, len(elements))
+	copy(current, elements)
+
+	//Heap's algorithm, stopped once enough orderings are collected
+	var permute func(k int)
+	permute = func(k int) {
+		if len(perms) >= maxShuffles {
+			return
+		}
+		if k == 1 {
+			perm := make([]string, len(current))
+			copy(perm, current)
+			perms = append(perms, perm)
+			return
+		}
+		for i := 0; i < k; i++ {
+			permute(k - 1)
+			if k%2 == 0 {
+				current[i], current[k-1] = current[k-1], current[i]
+			} else {
+				current[0], current[k-1] = current[k-1], current[0]
+			}
+		}
+	}
+	permute(len(current))
 
 	return perms
 }
@@ -550,52 +705,10 @@ func min(a, b int) int {
 	return b
 }
 
-func isDuplicatePath(newPath []model.Recipe, existingPaths [][]model.Recipe) bool {
-	if len(existingPaths) == 0 {
-		return false
-	}
-
-	newRecipes := make(map[string]bool)
-	for _, recipe := range newPath {
-		e1, e2 := recipe.Element1, recipe.Element2
-		if e1 > e2 {
-			e1, e2 = e2, e1
-		}
-		//Use the Result from Recipe
-		key := fmt.Sprintf("%s+%s->%s", e1, e2, recipe.Result)
-		newRecipes[key] = true
-	}
-
-	for _, existingPath := range existingPaths {
-		if len(existingPath) != len(newPath) {
-			continue
-		}
-
-		matches := true
-		existingRecipes := make(map[string]bool)
-		for _, recipe := range existingPath {
-			e1, e2 := recipe.Element1, recipe.Element2
-			if e1 > e2 {
-				e1, e2 = e2, e1
-			}
-			//Use the Result from Recipe
-			key := fmt.Sprintf("%s+%s->%s", e1, e2, recipe.Result)
-			existingRecipes[key] = true
-		}
-
-		for key := range newRecipes {
-			if !existingRecipes[key] {
-				matches = false
-				break
-			}
-		}
-
-		if matches {
-			return true
-		}
-	}
-
-	return false
+// Canonical key of the recipe tree a path builds, used to drop paths that
+// make the same tree with the steps in a different order.
+func pathTreeKey(path []model.Recipe, targetElement string, startElements []string) string {
+	return CanonicalKey(BuildTree(path, targetElement, startElements))
 }
 
 func isValidTierProgression(recipe model.Recipe, resultElement model.Element, db *model.ElementsDatabase) bool {
@@ -612,23 +725,23 @@ func isValidTierProgression(recipe model.Recipe, resultElement model.Element, db
 	return t1 < resultTier && t2 < resultTier
 }
 
-func Driver(db *model.ElementsDatabase, targetElement string, maxPaths int, step chan<- *SearchProgress) *BFSResult {
-	sortedDb := utility.SortByTier(db)
+func Driver(ctx context.Context, db *model.ElementsDatabase, startElements []string, targetElement string, maxPaths int,
+	strategy *SearchStrategy, step chan<- *SearchProgress) *BFSResult {
+	//db.Order is already tier-sorted (or seeded), so it is used as is
+	sortedDb := db
 	result := make(chan *BFSResult, 1)
-	startElement := []string{"Air", "Water", "Fire", "Earth"}
+	startElement := strategy.ownedElements(normalizeStartElements(startElements))
 	//Run BFS in a goroutine
 	if maxPaths == 1 {
-		go BFSSingle(sortedDb, startElement, targetElement, result, step)
+		go BFSSingle(ctx, sortedDb, startElement, targetElement, strategy, result, step)
 	} else if maxPaths > 1 {
-		go BFSMultipleThreaded(sortedDb, startElement, targetElement, maxPaths, 10, result)
+		go BFSMultipleThreaded(ctx, sortedDb, startElement, targetElement, maxPaths, MultiSearchTimeout, strategy, result, step)
 	} else {
-		result <- &BFSResult{
+		return &BFSResult{
 			TargetElement: targetElement,
 			Paths:         [][]model.Recipe{},
 			VisitedNodes:  0,
 		}
-		close(result)
-		return nil
 	}
 	//Wait for result
 	results := <-result
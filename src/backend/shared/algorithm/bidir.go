@@ -0,0 +1,270 @@
+package algorithm
+
+import (
+	"context"
+	"log"
+	"shared/model"
+	"shared/utility"
+	"sort"
+)
+
+type BidirResult struct {
+	TargetElement string           `json:"target_element"`
+	Paths         [][]model.Recipe `json:"recipes"`
+	VisitedNodes  int              `json:"visited_nodes"`
+}
+
+// Bidirectional search: the forward side grows the set of elements that can be
+// made from the start elements, the backward side grows the set of elements the
+// target needs (via Element.Recipes). Every element found by both sides is a
+// meeting point that gets turned into a path.
+func BidirectionalSearch(ctx context.Context, db *model.ElementsDatabase, startElements []string, targetElement string,
+	maxPaths int, result chan<- *BidirResult, progress chan<- *SearchProgress) {
+
+	defer close(result)
+
+	//Like BFS, an owned target has no path to find
+	if _, exists := db.Elements[targetElement]; !exists || inInventory(startElements, targetElement) {
+		result <- &BidirResult{
+			TargetElement: targetElement,
+			Paths:         [][]model.Recipe{},
+			VisitedNodes:  0,
+		}
+		return
+	}
+
+	//Forward side: element -> recipe that created it (nil for start elements)
+	forward := make(map[string]*model.Recipe)
+	forwardFrontier := []string{}
+	for _, elem := range startElements {
+		if _, seen := forward[elem]; !seen {
+			forward[elem] = nil
+			forwardFrontier = append(forwardFrontier, elem)
+		}
+	}
+
+	//Backward side: element -> recipe (with Result) of the element that needs it
+	backward := make(map[string]*model.Recipe)
+	backward[targetElement] = nil
+	backwardFrontier := []string{targetElement}
+
+	paths := [][]model.Recipe{}
+	pathKeys := make(map[string]bool)
+	met := make(map[string]bool)
+	visitedCount := 0
+
+	//Meeting points whose backward chain still has co-ingredients the
+	//forward side has not made yet
+	pending := make(map[string]bool)
+
+	collect := func(candidates []string, expandIncomplete bool) {
+		sort.Strings(candidates)
+		for _, meeting := range candidates {
+			if maxPaths > 0 && len(paths) >= maxPaths {
+				return
+			}
+			if met[meeting] {
+				continue
+			}
+			if !expandIncomplete && !bidirChainComplete(meeting, forward, backward) {
+				pending[meeting] = true
+				continue
+			}
+			met[meeting] = true
+			delete(pending, meeting)
+
+			path := assembleBidirPath(meeting, forward, backward, startElements)
+			path = iterativeExpansion(ctx, path, db, startElements, nil, progress)
+			if !isCompletePath(path, startElements) {
+				continue
+			}
+			key := pathTreeKey(path, targetElement, startElements)
+			if !pathKeys[key] {
+				pathKeys[key] = true
+				paths = append(paths, path)
+				log.Printf("Bidir: met at %s, path with %d steps", meeting, len(path))
+			}
+		}
+	}
+
+	layer := 0
+	for (maxPaths <= 0 || len(paths) < maxPaths) && (len(forwardFrontier) > 0 || len(backwardFrontier) > 0) {
+		if ctx.Err() != nil {
+			log.Printf("Bidir for %s cancelled: %v", targetElement, ctx.Err())
+			break
+		}
+		layer++
+		meetings := []string{}
+
+		//Expand the smaller non-empty frontier first
+		expandForward := len(backwardFrontier) == 0 ||
+			(len(forwardFrontier) > 0 && len(forwardFrontier) <= len(backwardFrontier))
+
+		if expandForward {
+			for _, elem := range forwardFrontier {
+				visitedCount++
+				sendBidirProgress(progress, elem, visitedCount, len(paths), len(forwardFrontier), layer)
+			}
+
+			next := []string{}
+			for _, elem := range forwardFrontier {
+				for _, recipe := range utility.UsesOf(db, elem) {
+					if _, known := forward[recipe.Result]; known {
+						continue
+					}
+					_, ok1 := forward[recipe.Element1]
+					_, ok2 := forward[recipe.Element2]
+					element, exists := db.Elements[recipe.Result]
+					if !ok1 || !ok2 || !exists || !isValidTierProgression(recipe, element, db) {
+						continue
+					}
+					discovered := recipe
+					forward[recipe.Result] = &discovered
+					next = append(next, recipe.Result)
+					if _, needed := backward[recipe.Result]; needed {
+						meetings = append(meetings, recipe.Result)
+					}
+				}
+			}
+			forwardFrontier = next
+		} else {
+			next := []string{}
+			for _, elem := range backwardFrontier {
+				visitedCount++
+				sendBidirProgress(progress, elem, visitedCount, len(paths), len(backwardFrontier), layer)
+
+				element, exists := db.Elements[elem]
+				if !exists {
+					continue
+				}
+				for _, recipe := range element.Recipes {
+					if !isValidTierProgression(recipe, element, db) {
+						continue
+					}
+					for _, ingredient := range []string{recipe.Element1, recipe.Element2} {
+						if _, known := backward[ingredient]; known {
+							continue
+						}
+						backward[ingredient] = &model.Recipe{
+							Element1: recipe.Element1,
+							Element2: recipe.Element2,
+							Result:   elem,
+						}
+						next = append(next, ingredient)
+						if _, made := forward[ingredient]; made {
+							meetings = append(meetings, ingredient)
+						}
+					}
+				}
+			}
+			backwardFrontier = next
+		}
+
+		collect(append(meetings, keysFromMap(pending)...), false)
+	}
+
+	//Frontiers exhausted: fall back to iterative expansion for the rest
+	if ctx.Err() == nil {
+		collect(keysFromMap(pending), true)
+	}
+
+	result <- &BidirResult{
+		TargetElement: targetElement,
+		Paths:         paths,
+		VisitedNodes:  visitedCount,
+	}
+}
+
+// Build a path through a meeting element: the forward recipes that make it,
+// followed by the backward chain from it up to the target.
+func assembleBidirPath(meeting string, forward, backward map[string]*model.Recipe, startElements []string) []model.Recipe {
+	made := make(map[string]bool)
+	for _, elem := range startElements {
+		made[elem] = true
+	}
+	path := []model.Recipe{}
+
+	var addForward func(elem string)
+	addForward = func(elem string) {
+		if made[elem] {
+			return
+		}
+		recipe, ok := forward[elem]
+		if !ok || recipe == nil {
+			return
+		}
+		addForward(recipe.Element1)
+		addForward(recipe.Element2)
+		made[elem] = true
+		path = append(path, *recipe)
+	}
+
+	addForward(meeting)
+
+	current := meeting
+	for {
+		recipe := backward[current]
+		if recipe == nil {
+			break
+		}
+		//Co-ingredients that the forward side already knows how to make
+		addForward(recipe.Element1)
+		addForward(recipe.Element2)
+		if !made[recipe.Result] {
+			made[recipe.Result] = true
+			path = append(path, *recipe)
+		}
+		current = recipe.Result
+	}
+
+	return path
+}
+
+// A meeting point is complete when every recipe on its backward chain has both
+// ingredients on the forward side.
+func bidirChainComplete(meeting string, forward, backward map[string]*model.Recipe) bool {
+	if _, ok := forward[meeting]; !ok {
+		return false
+	}
+	current := meeting
+	for {
+		recipe := backward[current]
+		if recipe == nil {
+			return true
+		}
+		_, ok1 := forward[recipe.Element1]
+		_, ok2 := forward[recipe.Element2]
+		if !ok1 || !ok2 {
+			return false
+		}
+		current = recipe.Result
+	}
+}
+
+// Counts only: copying the discovered set for every node would make progress
+// cost more than the search itself.
+func sendBidirProgress(progress chan<- *SearchProgress, current string, visited int, found int, frontier int, layer int) {
+	if progress == nil {
+		return
+	}
+	select {
+	case progress <- &SearchProgress{
+		CurrentElement: current,
+		Visited:        visited,
+		PathsFound:     found,
+		Frontier:       frontier,
+		Depth:          layer,
+	}:
+	default:
+	}
+}
+
+func BidirDriver(ctx context.Context, db *model.ElementsDatabase, startElements []string, targetElement string, maxPaths int, step chan<- *SearchProgress) *BidirResult {
+	result := make(chan *BidirResult, 1)
+	startElements = normalizeStartElements(startElements)
+	if maxPaths < 1 {
+		maxPaths = 1
+	}
+	go BidirectionalSearch(ctx, db, startElements, targetElement, maxPaths, result, step)
+	return <-result
+}
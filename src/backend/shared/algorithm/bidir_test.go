@@ -0,0 +1,51 @@
+package algorithm
+
+import (
+	"context"
+	"shared/utility"
+	"testing"
+)
+
+func TestBidirFindsCompletePaths(t *testing.T) {
+	db := loadGameDatabase(t)
+	start := utility.DefaultStartElements
+	for _, target := range []string{"Mud", "Brick", "Human", "Bread"} {
+		res := BidirDriver(context.Background(), db, start, target, 3, nil)
+		if len(res.Paths) == 0 {
+			t.Errorf("%s: no path", target)
+		}
+		for _, path := range res.Paths {
+			if !isCompletePath(path, start) {
+				t.Errorf("%s: incomplete path %v", target, path)
+			}
+		}
+	}
+}
+
+// A target that is already owned has nothing to make, for every path search.
+// ENUM is left out: it lists recipe trees, and an owned element is one leaf tree
+// just as CountRecipeTrees counts it.
+func TestOwnedTargetHasNoPaths(t *testing.T) {
+	db := tinyDatabase()
+	ctx := context.Background()
+	for _, tt := range []struct {
+		start  []string
+		target string
+	}{
+		{nil, "Fire"},
+		{[]string{"Mud", "Fire"}, "Mud"},
+	} {
+		results := map[string]int{
+			"BFS":      len(Driver(ctx, db, tt.start, tt.target, 1, nil, nil).Paths),
+			"DFS":      len(MultiDFS(ctx, db, tt.start, tt.target, 1, nil, nil).Paths),
+			"BIDIR":    len(BidirDriver(ctx, db, tt.start, tt.target, 1, nil).Paths),
+			"OPTIMAL":  len(OptimalDriver(ctx, db, tt.start, tt.target, nil).Paths),
+			"WEIGHTED": len(WeightedDriver(ctx, db, tt.start, tt.target, nil, nil).Paths),
+		}
+		for method, paths := range results {
+			if paths != 0 {
+				t.Errorf("%s %s from %v: got %d paths, want none", method, tt.target, tt.start, paths)
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package algorithm
+
+import (
+	"context"
+	"sync"
+)
+
+// Lets a caller pause, resume and single-step a running search. Searches call
+// Wait before expanding each node; a nil *Controller never blocks.
+type Controller struct {
+	mu     sync.Mutex
+	paused bool
+	steps  int
+	wake   chan struct{}
+}
+
+func NewController() *Controller {
+	return &Controller{wake: make(chan struct{})}
+}
+
+func (c *Controller) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paused = true
+	c.steps = 0
+}
+
+func (c *Controller) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paused = false
+	c.signal()
+}
+
+// Let exactly one more node through while paused.
+func (c *Controller) Step() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		c.steps++
+		c.signal()
+	}
+}
+
+func (c *Controller) Paused() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused
+}
+
+// Block while paused. Returns the context error if it ends first.
+func (c *Controller) Wait(ctx context.Context) error {
+	if c == nil {
+		return ctx.Err()
+	}
+	for {
+		c.mu.Lock()
+		if !c.paused {
+			c.mu.Unlock()
+			return ctx.Err()
+		}
+		if c.steps > 0 {
+			c.steps--
+			c.mu.Unlock()
+			return ctx.Err()
+		}
+		wake := c.wake
+		c.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Wake every waiter; the caller holds c.mu.
+func (c *Controller) signal() {
+	close(c.wake)
+	c.wake = make(chan struct{})
+}
+
+// Send a progress event. Without a controller a slow receiver just misses
+// events; while stepping through a search every node has to arrive, so the
+// send waits for the receiver.
+func (c *Controller) send(ctx context.Context, step chan<- *SearchProgress, progress *SearchProgress) {
+	if c == nil {
+		select {
+		case step <- progress:
+		default:
+		}
+		return
+	}
+	select {
+	case step <- progress:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,59 @@
+package algorithm
+
+import (
+	"fmt"
+	"shared/model"
+	"shared/utility"
+)
+
+// Cost of making an element once. A per-element cost wins over a per-tier
+// cost, which wins over the formula Base + PerTier * tier of the element.
+type CostModel struct {
+	Element map[string]float64
+	Tier    map[int]float64
+	Base    float64
+	PerTier float64
+}
+
+func NewCostModel(req model.SearchRequest) (*CostModel, error) {
+	costs := &CostModel{
+		Element: req.ElementCosts,
+		Tier:    req.TierCosts,
+		Base:    1,
+		PerTier: req.TierCost,
+	}
+	if req.BaseCost != nil {
+		costs.Base = *req.BaseCost
+	}
+
+	//Negative costs would break the lower bounds of the solver
+	if costs.Base < 0 || costs.PerTier < 0 {
+		return nil, fmt.Errorf("costs must not be negative")
+	}
+	for name, cost := range costs.Element {
+		if cost < 0 {
+			return nil, fmt.Errorf("cost of %q must not be negative", name)
+		}
+	}
+	for tier, cost := range costs.Tier {
+		if cost < 0 {
+			return nil, fmt.Errorf("cost of tier %d must not be negative", tier)
+		}
+	}
+	return costs, nil
+}
+
+// A nil model costs 1 per element, i.e. counts combinations.
+func (c *CostModel) elementCost(db *model.ElementsDatabase, elem string) float64 {
+	if c == nil {
+		return 1
+	}
+	if cost, ok := c.Element[elem]; ok {
+		return cost
+	}
+	tier := utility.ParseTier(db.Elements[elem].Tier)
+	if cost, ok := c.Tier[tier]; ok {
+		return cost
+	}
+	return c.Base + c.PerTier*float64(tier)
+}
@@ -0,0 +1,52 @@
+package algorithm
+
+import (
+	"math/big"
+	"shared/model"
+)
+
+// Number of distinct recipe trees of every element in the database, counted
+// over the same tier-valid recipes EnumerateTrees walks: owned elements count
+// as one tree, unreachable elements as zero.
+func CountRecipeTrees(db *model.ElementsDatabase, startElements []string) map[string]*big.Int {
+	table := newRecipeTable(db, normalizeStartElements(startElements))
+
+	counts := make(map[string]*big.Int, len(db.Elements))
+	for name := range db.Elements {
+		counts[name] = big.NewInt(0)
+	}
+	for elem := range table.owned {
+		counts[elem] = big.NewInt(1)
+	}
+
+	//Ingredients come earlier in table.order, so their counts are final
+	for _, elem := range table.order {
+		total := big.NewInt(0)
+		product := new(big.Int)
+		for _, recipe := range table.recipes[elem] {
+			left, right := counts[recipe.Element1], counts[recipe.Element2]
+			if left == nil || right == nil {
+				continue
+			}
+			if recipe.Element1 == recipe.Element2 {
+				//X+X: unordered pairs of X's trees, n*(n+1)/2
+				product.Add(left, big.NewInt(1))
+				product.Mul(product, left)
+				product.Rsh(product, 1)
+				total.Add(total, product)
+				continue
+			}
+			total.Add(total, product.Mul(left, right))
+		}
+		counts[elem] = total
+	}
+
+	return counts
+}
+
+func CountRecipeTreesFor(db *model.ElementsDatabase, startElements []string, targetElement string) *big.Int {
+	if count, ok := CountRecipeTrees(db, startElements)[targetElement]; ok {
+		return count
+	}
+	return big.NewInt(0)
+}
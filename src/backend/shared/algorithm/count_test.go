@@ -0,0 +1,56 @@
+package algorithm
+
+import (
+	"context"
+	"math/big"
+	"shared/utility"
+	"testing"
+)
+
+func TestCountRecipeTreesTinyDatabase(t *testing.T) {
+	counts := CountRecipeTrees(tinyDatabase(), nil)
+	tests := []struct {
+		element string
+		want    int64
+	}{
+		{"Water", 1},
+		{"Mud", 1},
+		{"Steam", 1},
+		{"Brick", 2},
+		{"Wall", 5},
+	}
+	for _, tt := range tests {
+		if got := counts[tt.element]; got.Cmp(big.NewInt(tt.want)) != 0 {
+			t.Errorf("%s: got %s trees, want %d", tt.element, got, tt.want)
+		}
+	}
+}
+
+// With a limit above the count, ENUM lists exactly the trees that are counted.
+func TestCountMatchesEnumeration(t *testing.T) {
+	db := loadGameDatabase(t)
+	start := utility.DefaultStartElements
+	counts := CountRecipeTrees(db, start)
+
+	checked := 0
+	for _, elem := range db.Order {
+		count := counts[elem]
+		if count.Sign() == 0 || count.Cmp(big.NewInt(200)) > 0 {
+			continue
+		}
+		res := EnumerateDriver(context.Background(), db, start, elem, 201)
+		if int64(len(res.Trees)) != count.Int64() {
+			t.Errorf("%s: counted %s trees, enumerated %d", elem, count, len(res.Trees))
+		}
+		checked++
+	}
+	if checked == 0 {
+		t.Fatal("no element with a small tree count")
+	}
+}
+
+func TestCountRecipeTreesForUnknownElement(t *testing.T) {
+	if got := CountRecipeTreesFor(tinyDatabase(), nil, "Unobtainium"); got.Sign() != 0 {
+		t.Errorf("got %s, want 0", got)
+	}
+}
@@ -0,0 +1,57 @@
+package algorithm
+
+import (
+	"shared/model"
+	"shared/utility"
+	"sort"
+)
+
+type CraftableElement struct {
+	Name    string         `json:"name"`
+	Tier    string         `json:"tier"`
+	Icon    string         `json:"icon"`
+	Recipes []model.Recipe `json:"recipes"` // pairs of owned elements that make it
+}
+
+// One step forward from an inventory: every pair of owned elements that makes
+// something not owned yet, grouped by result in tier order. The pairs are the
+// same tier-valid combinations the BFS main loop tries.
+func CraftableNow(db *model.ElementsDatabase, owned []string) []CraftableElement {
+	owned = normalizeStartElements(owned)
+	isOwned := make(map[string]bool, len(owned))
+	for _, elem := range owned {
+		isOwned[elem] = true
+	}
+
+	byResult := make(map[string][]model.Recipe)
+	for i, e1 := range owned {
+		for _, e2 := range owned[i:] {
+			for _, recipe := range utility.CombinationResults(db, e1, e2) {
+				resultElement, ok := db.Elements[recipe.Result]
+				if isOwned[recipe.Result] || !ok || !isValidTierProgression(recipe, resultElement, db) {
+					continue
+				}
+				byResult[recipe.Result] = append(byResult[recipe.Result], recipe)
+			}
+		}
+	}
+
+	craftable := make([]CraftableElement, 0, len(byResult))
+	for name, recipes := range byResult {
+		element := db.Elements[name]
+		craftable = append(craftable, CraftableElement{
+			Name:    name,
+			Tier:    element.Tier,
+			Icon:    element.Icon,
+			Recipes: recipes,
+		})
+	}
+	sort.Slice(craftable, func(i, j int) bool {
+		ti, tj := utility.ParseTier(craftable[i].Tier), utility.ParseTier(craftable[j].Tier)
+		if ti != tj {
+			return ti < tj
+		}
+		return craftable[i].Name < craftable[j].Name
+	})
+	return craftable
+}
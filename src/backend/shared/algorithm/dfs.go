@@ -1,6 +1,7 @@
 package algorithm
 
 import (
+	"context"
 	"fmt"
 	"shared/model"
 	"shared/utility"
@@ -18,7 +19,178 @@ type DFSNode struct {
 	ParentNode *DFSNode
 }
 
-func DFS(db *model.ElementsDatabase, startElements []string, targetElement string, maxPath int, result chan<- *DFSResult, step chan<- *SearchProgress) {
+func DFS(ctx context.Context, db *model.ElementsDatabase, startElements []string, targetElement string, maxPath int,
+	strategy *SearchStrategy, result chan<- *DFSResult, step chan<- *SearchProgress) {
+	dfsFromRoots(ctx, db, startElements, startElements, targetElement, maxPath, strategy, result, step)
+}
+
+// One entry of the explicit DFS stack. uses/next and pending remember where
+// the loop over combinations of Element stopped, like the locals of the
+// recursive version did.
+type dfsFrame struct {
+	Element string
+	Path    []model.Recipe
+	Depth   int
+	uses    []model.Recipe
+	next    int
+	pending []model.Recipe // results of the current pair not descended into yet
+}
+
+// State of a single depth-limited DFS pass.
+type dfsRun struct {
+	ctx           context.Context
+	db            *model.ElementsDatabase
+	targetElement string
+	maxPath       int
+	maxDepth      int // 0 = tanpa batas
+	startElements []string
+	strategy      *SearchStrategy
+	control       *Controller
+	step          chan<- *SearchProgress
+
+	reachable           map[string]bool
+	visitedCombinations map[string]bool
+	expandedDepth       map[string]int // shallowest depth each pair was expanded at
+	stack               []*dfsFrame
+	paths               [][]model.Recipe
+	visitedCount        int
+	cutoff              bool           // some node was not expanded because of maxDepth
+	tried               []model.Recipe // combinations tried since the last progress event
+}
+
+func (run *dfsRun) stopped() bool {
+	return len(run.paths) >= run.maxPath || run.ctx.Err() != nil
+}
+
+// Visit a node: record it if it is the target, otherwise push it so its
+// combinations get explored.
+func (run *dfsRun) visit(current string, path []model.Recipe, depth int) {
+	if err := run.control.Wait(run.ctx); err != nil {
+		return // Pencarian dibatalkan atau timeout.
+	}
+
+	run.visitedCount++
+	if run.step != nil {
+		select {
+		case run.step <- &SearchProgress{
+			CurrentElement: current,
+			Visited:        run.visitedCount,
+			PathsFound:     len(run.paths),
+			VisitedNodes:   run.visitedCombinations,
+			Frontier:       len(run.stack),
+			Depth:          depth,
+			Via:            lastRecipe(path),
+			Tried:          run.tried,
+		}:
+			run.tried = nil
+		case <-run.ctx.Done():
+			return
+		}
+	}
+
+	if current == run.targetElement {
+		newPath := make([]model.Recipe, len(path))
+		copy(newPath, path)
+		if run.strategy.hasRequirements() {
+			//Only the expanded path shows every element the recipe really uses
+			newPath = expandPath(newPath, run.db, run.startElements, run.strategy)
+			if !run.strategy.includesRequired(newPath) {
+				return
+			}
+		}
+		run.paths = append(run.paths, newPath)
+		return
+	}
+
+	if run.maxDepth > 0 && depth >= run.maxDepth {
+		run.cutoff = true
+		return
+	}
+
+	run.stack = append(run.stack, &dfsFrame{
+		Element: current,
+		Path:    path,
+		Depth:   depth,
+		uses:    run.strategy.preferredFirst(utility.UsesOf(run.db, current), run.db),
+	})
+}
+
+func (run *dfsRun) search(rootElements []string) {
+	for _, basicElement := range rootElements {
+		if run.stopped() {
+			return // Hentikan jika sudah menemukan cukup banyak jalur.
+		}
+		run.visit(basicElement, []model.Recipe{}, 0)
+
+		for len(run.stack) > 0 && !run.stopped() {
+			top := run.stack[len(run.stack)-1]
+
+			//Descend into the next result of the current combination
+			if len(top.pending) > 0 {
+				recipe := top.pending[0]
+				top.pending = top.pending[1:]
+				newPath := make([]model.Recipe, len(top.Path)+1)
+				copy(newPath, top.Path)
+				newPath[len(top.Path)] = recipe
+				run.visit(recipe.Result, newPath, top.Depth+1)
+				continue
+			}
+
+			if top.next >= len(top.uses) {
+				run.stack = run.stack[:len(run.stack)-1]
+				continue
+			}
+
+			use := top.uses[top.next]
+			top.next++
+
+			//Cek kombinasi current dengan element lain.
+			elementID := use.Element2
+			if use.Element1 != top.Element {
+				elementID = use.Element1
+			}
+			if !run.reachable[elementID] {
+				continue
+			}
+			//Needing the target to make the target is a cycle once tiers are relaxed
+			if run.strategy.relaxed() && elementID == run.targetElement {
+				continue
+			}
+			//With a depth limit a pair reached higher up the stack has more depth
+			//left than when it was expanded, so it is expanded again
+			combinationKey := utility.CombinationKey(top.Element, elementID)
+			if depth, seen := run.expandedDepth[combinationKey]; seen && (run.maxDepth == 0 || depth <= top.Depth) {
+				continue
+			}
+			run.expandedDepth[combinationKey] = top.Depth
+			run.visitedCombinations[combinationKey] = true
+
+			for _, recipe := range utility.CombinationResults(run.db, top.Element, elementID) {
+				resultElement, ok := run.db.Elements[recipe.Result]
+				if !ok || !run.strategy.allowsRecipe(recipe) || !run.strategy.allowsTier(recipe, resultElement, run.db) {
+					continue
+				}
+				//Without the tier rule an element could be made again further down its own path
+				if run.strategy.relaxed() && pathMakes(top.Path, recipe.Result, run.startElements) {
+					continue
+				}
+				top.pending = append(top.pending, recipe)
+				if run.step != nil {
+					run.tried = append(run.tried, recipe)
+				}
+			}
+		}
+		run.stack = run.stack[:0]
+	}
+}
+
+// Deepest limit tried by IDDFS when the strategy does not set MaxDepth
+const defaultIDDFSMaxDepth = 32
+
+// DFS starting only from rootElements, while every element of startElements
+// counts as owned when pairing and expanding paths.
+func dfsFromRoots(ctx context.Context, db *model.ElementsDatabase, startElements []string, rootElements []string, targetElement string,
+	maxPath int, strategy *SearchStrategy, result chan<- *DFSResult, step chan<- *SearchProgress) {
 	target, exists := db.Elements[targetElement]
 	if !exists {
 		result <- &DFSResult{
@@ -29,7 +201,7 @@ func DFS(db *model.ElementsDatabase, startElements []string, targetElement strin
 		close(result)
 		return
 	}
-	if target.IsBasic {
+	if target.IsBasic || inInventory(startElements, targetElement) {
 		result <- &DFSResult{
 			TargetElement: targetElement,
 			Paths:         [][]model.Recipe{},
@@ -39,77 +211,52 @@ func DFS(db *model.ElementsDatabase, startElements []string, targetElement strin
 		return
 	}
 
-	visitedCombinations := make(map[string]bool)
-	paths := make([][]model.Recipe, 0)
-	visitedCount := 0
-
-	var dfsRecursive func(current string, path []model.Recipe, depth int)
-	dfsRecursive = func(current string, path []model.Recipe, depth int) {
-		if len(paths) >= maxPath {
-			return // Hentikan jika sudah menemukan cukup banyak jalur.
-		}
-
-		visitedCount++
-		if step != nil {
-			step <- &SearchProgress{
-				CurrentElement: current,
-				Visited:        visitedCount,
-				PathsFound:     len(paths),
-				VisitedNodes:   visitedCombinations,
-			}
+	if strategy == nil {
+		strategy = &SearchStrategy{}
+	}
+	reachable := reachableFrom(db, startElements, strategy)
+	newRun := func(maxDepth int) *dfsRun {
+		return &dfsRun{
+			ctx:                 ctx,
+			db:                  db,
+			targetElement:       targetElement,
+			maxPath:             maxPath,
+			maxDepth:            maxDepth,
+			startElements:       startElements,
+			strategy:            strategy,
+			control:             strategy.Control,
+			step:                step,
+			reachable:           reachable,
+			visitedCombinations: make(map[string]bool),
+			expandedDepth:       make(map[string]int),
 		}
+	}
 
-		if current == targetElement {
-			newPath := make([]model.Recipe, len(path))
-			copy(newPath, path)
-			paths = append(paths, newPath)
-			return
+	var run *dfsRun
+	visitedCount := 0
+	if strategy.Type == "IDDFS" {
+		//Iterative deepening: shallow trees are found before deeper ones
+		limit := strategy.MaxDepth
+		if limit <= 0 {
+			limit = defaultIDDFSMaxDepth
 		}
-
-		for elementID := range db.Elements {
-			//Cek kombinasi current dengan element lain.
-			e1, e2 := current, elementID
-			if e1 > e2 {
-				e1, e2 = e2, e1
-			}
-			combinationKey := fmt.Sprintf("%s+%s", e1, e2)
-
-			if visitedCombinations[combinationKey] {
-				continue
-			}
-
-			visitedCombinations[combinationKey] = true
-
-			for resultElementID, resultElement := range db.Elements {
-				resultTier := utility.ParseTier(resultElement.Tier)
-				for _, recipe := range resultElement.Recipes {
-					if (recipe.Element1 == e1 && recipe.Element2 == e2) || (recipe.Element1 == e2 && recipe.Element2 == e1) {
-						r1, ok1 := db.Elements[recipe.Element1]
-						r2, ok2 := db.Elements[recipe.Element2]
-						if !ok1 || !ok2 {
-							continue
-						}
-						t1 := utility.ParseTier(r1.Tier)
-						t2 := utility.ParseTier(r2.Tier)
-						if t1 >= resultTier || t2 >= resultTier {
-							continue
-						}
-						newPath := make([]model.Recipe, len(path)+1)
-						copy(newPath, path)
-						newPath[len(path)] = recipe
-						dfsRecursive(resultElementID, newPath, depth+1)
-					}
-				}
+		for depth := 1; depth <= limit; depth++ {
+			run = newRun(depth)
+			run.search(rootElements)
+			visitedCount += run.visitedCount
+			if len(run.paths) > 0 || !run.cutoff || ctx.Err() != nil {
+				break
 			}
 		}
+	} else {
+		run = newRun(strategy.MaxDepth)
+		run.search(rootElements)
+		visitedCount = run.visitedCount
 	}
 
-	for _, basicElement := range startElements {
-		dfsRecursive(basicElement, []model.Recipe{}, 0)
-	}
-
+	paths := run.paths
 	for i := range paths {
-		paths[i] = expandPath(paths[i], db, startElements)
+		paths[i] = expandPath(paths[i], db, startElements, strategy)
 	}
 
 	result <- &DFSResult{
@@ -120,10 +267,11 @@ func DFS(db *model.ElementsDatabase, startElements []string, targetElement strin
 	close(result)
 }
 
-func expandPath(path []model.Recipe, db *model.ElementsDatabase, startElements []string) []model.Recipe {
+func expandPath(path []model.Recipe, db *model.ElementsDatabase, startElements []string, strategy *SearchStrategy) []model.Recipe {
 	isBasic := make(map[string]bool)
 	elementRecipes := make(map[string]model.Recipe)
 	visited := make(map[string]bool)
+	ranks := reachableRanks(db, startElements, strategy)
 	// Mark basic elements
 	for _, elem := range startElements {
 		isBasic[elem] = true
@@ -152,7 +300,25 @@ func expandPath(path []model.Recipe, db *model.ElementsDatabase, startElements [
 			return []model.Recipe{}
 		}
 
-		recipe := element.Recipes[0] // Take first recipe
+		// Take the first recipe that can be made from the inventory. Without
+		// the tier rule its ingredients must also be reached earlier, or the
+		// element could end up depending on itself.
+		recipe := element.Recipes[0]
+		rank, reached := ranks[elementID]
+		for _, candidate := range strategy.preferredFirst(element.Recipes, db) {
+			candidate.Result = elementID
+			r1, ok1 := ranks[candidate.Element1]
+			r2, ok2 := ranks[candidate.Element2]
+			if !ok1 || !ok2 || !strategy.allowsRecipe(candidate) || !strategy.allowsTier(candidate, element, db) {
+				continue
+			}
+			if strategy.relaxed() && reached && (r1 >= rank || r2 >= rank) {
+				continue
+			}
+			recipe = candidate
+			break
+		}
+		recipe.Result = elementID
 
 		// Get dependencies for both ingredients
 		deps := []model.Recipe{}
@@ -180,7 +346,7 @@ func expandPath(path []model.Recipe, db *model.ElementsDatabase, startElements [
 	seen := make(map[string]bool)
 	uniqueRecipes := []model.Recipe{}
 	for _, recipe := range allRecipes {
-		key := fmt.Sprintf("%s+%s", recipe.Element1, recipe.Element2)
+		key := fmt.Sprintf("%s+%s->%s", recipe.Element1, recipe.Element2, recipe.Result)
 		if !seen[key] {
 			seen[key] = true
 			uniqueRecipes = append(uniqueRecipes, recipe)
@@ -190,30 +356,54 @@ func expandPath(path []model.Recipe, db *model.ElementsDatabase, startElements [
 	return uniqueRecipes
 }
 
-func MultiDFS(db *model.ElementsDatabase, targetElement string, maxPath int, step chan<- *SearchProgress) *DFSResult {
-	sortedDb := utility.SortByTier(db)
+func MultiDFS(ctx context.Context, db *model.ElementsDatabase, startElements []string, targetElement string, maxPath int,
+	strategy *SearchStrategy, step chan<- *SearchProgress) *DFSResult {
+	//db.Order is already tier-sorted (or seeded), so it is used as is
+	sortedDb := db
 
-	startElements := []string{"Air", "Water", "Fire", "Earth"}
+	startElements = strategy.ownedElements(normalizeStartElements(startElements))
 
-	resultChan := make(chan *DFSResult, len(startElements))
+	resultChan := make(chan indexedDFSResult, len(startElements))
 
-	for _, elem := range startElements {
-		go func(start string) {
-			DFS(sortedDb, []string{start}, targetElement, maxPath, resultChan, step)
-		}(elem)
+	searchRoot := func(index int, start string) {
+		// Each DFS closes its own result channel, so give every root its own
+		rootResult := make(chan *DFSResult, 1)
+		dfsFromRoots(ctx, sortedDb, startElements, []string{start}, targetElement, maxPath, strategy, rootResult, step)
+		resultChan <- indexedDFSResult{Index: index, Result: <-rootResult}
+	}
+	if strategy.controller() != nil {
+		//Step-through shows the roots one after another instead of whichever goroutine wins
+		go func() {
+			for i, elem := range startElements {
+				searchRoot(i, elem)
+			}
+		}()
+	} else {
+		for i, elem := range startElements {
+			go searchRoot(i, elem)
+		}
+	}
+
+	// Kumpulkan per urutan start element agar hasil tidak bergantung goroutine mana yang selesai duluan
+	byRoot := make([]*DFSResult, len(startElements))
+	for collected := 0; collected < len(startElements); collected++ {
+		res := <-resultChan
+		byRoot[res.Index] = res.Result
 	}
 
+	//Every root pairs against the whole inventory, so roots can find the same tree
 	finalPaths := [][]model.Recipe{}
+	seen := make(map[string]bool)
 	totalVisited := 0
-	collected := 0
-
-	for res := range resultChan {
-		finalPaths = append(finalPaths, res.Paths...)
+	for _, res := range byRoot {
 		totalVisited += res.VisitedNodes
-		collected++
-
-		if collected == len(startElements) {
-			close(resultChan)
+		for _, path := range res.Paths {
+			key := pathTreeKey(path, targetElement, startElements)
+			if seen[key] || len(finalPaths) >= maxPath {
+				continue
+			}
+			seen[key] = true
+			finalPaths = append(finalPaths, path)
 		}
 	}
 
@@ -223,3 +413,21 @@ func MultiDFS(db *model.ElementsDatabase, targetElement string, maxPath int, ste
 		VisitedNodes:  totalVisited,
 	}
 }
+
+type indexedDFSResult struct {
+	Index  int
+	Result *DFSResult
+}
+
+// Whether the element is owned or already made somewhere on the path.
+func pathMakes(path []model.Recipe, element string, startElements []string) bool {
+	if inInventory(startElements, element) {
+		return true
+	}
+	for _, recipe := range path {
+		if recipe.Result == element {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package algorithm
+
+import (
+	"context"
+	"shared/utility"
+	"testing"
+)
+
+// A depth limit must not hide paths that fit in it, even when a pair was
+// already expanded deeper down the stack.
+func TestDepthLimitedDFSFindsMinimalDepth(t *testing.T) {
+	db := loadGameDatabase(t)
+	ctx := context.Background()
+	start := utility.DefaultStartElements
+	tests := []struct {
+		target string
+		depth  int
+	}{
+		{"Planet", 2},
+		{"Tornado", 2},
+		{"Gold", 2},
+		{"House", 3},
+		{"Solar system", 3},
+	}
+	for _, tt := range tests {
+		for _, method := range []string{"DFS", "IDDFS"} {
+			res := MultiDFS(ctx, db, start, tt.target, 1, &SearchStrategy{Type: method, MaxDepth: tt.depth}, nil)
+			if len(res.Paths) != 1 {
+				t.Errorf("%s %s at depth %d: found %d paths", method, tt.target, tt.depth, len(res.Paths))
+				continue
+			}
+			if !isCompletePath(res.Paths[0], start) {
+				t.Errorf("%s %s: incomplete path %v", method, tt.target, res.Paths[0])
+			}
+		}
+		if res := MultiDFS(ctx, db, start, tt.target, 1, &SearchStrategy{Type: "IDDFS", MaxDepth: tt.depth - 1}, nil); len(res.Paths) != 0 {
+			t.Errorf("%s: found at depth %d, expected %d to be minimal", tt.target, tt.depth-1, tt.depth)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package algorithm
+
+import (
+	"context"
+	"shared/model"
+	"strings"
+)
+
+type EnumerateResult struct {
+	TargetElement string            `json:"target_element"`
+	Trees         []*model.TreeNode `json:"trees"`
+	Paths         [][]model.Recipe  `json:"recipes"`
+	VisitedNodes  int               `json:"visited_nodes"`
+}
+
+// Enumerates distinct recipe trees. Two trees are different when any node,
+// not only the target, is made with a different recipe. Every subtree list is
+// capped at limit, which is enough because a tree only ever uses the first
+// limit subtrees of each ingredient.
+type treeEnumerator struct {
+	ctx     context.Context
+	table   *recipeTable
+	limit   int
+	memo    map[string][]*model.TreeNode
+	visited int
+}
+
+func (en *treeEnumerator) trees(element string) []*model.TreeNode {
+	if trees, ok := en.memo[element]; ok {
+		return trees
+	}
+	if en.ctx.Err() != nil {
+		return nil
+	}
+	en.visited++
+
+	if en.table.owned[element] {
+		leaf := []*model.TreeNode{{Name: element}}
+		en.memo[element] = leaf
+		return leaf
+	}
+
+	//Subtrees per recipe, each in diagonal order so both sides vary early
+	perRecipe := [][]*model.TreeNode{}
+	for _, recipe := range en.table.recipes[element] {
+		left := en.trees(recipe.Element1)
+		right := en.trees(recipe.Element2)
+		perRecipe = append(perRecipe, combineTrees(element, recipe, left, right, en.limit))
+	}
+
+	//Round robin over recipes so the first trees already differ at the top
+	trees := []*model.TreeNode{}
+	for i := 0; len(trees) < en.limit; i++ {
+		added := false
+		for _, candidates := range perRecipe {
+			if i < len(candidates) && len(trees) < en.limit {
+				trees = append(trees, candidates[i])
+				added = true
+			}
+		}
+		if !added {
+			break
+		}
+	}
+
+	en.memo[element] = trees
+	return trees
+}
+
+func combineTrees(element string, recipe model.Recipe, left, right []*model.TreeNode, limit int) []*model.TreeNode {
+	//Canonical order of the children: ingredient names ascending
+	if recipe.Element1 > recipe.Element2 {
+		recipe.Element1, recipe.Element2 = recipe.Element2, recipe.Element1
+		left, right = right, left
+	}
+
+	combined := []*model.TreeNode{}
+	for sum := 0; sum < len(left)+len(right)-1 && len(combined) < limit; sum++ {
+		for i := 0; i <= sum && len(combined) < limit; i++ {
+			j := sum - i
+			if i >= len(left) || j >= len(right) {
+				continue
+			}
+			//X+X: (i, j) and (j, i) are the same tree with the children swapped
+			if recipe.Element1 == recipe.Element2 && j < i {
+				continue
+			}
+			step := recipe
+			combined = append(combined, &model.TreeNode{
+				Name:     element,
+				Recipe:   &step,
+				Children: []*model.TreeNode{left[i], right[j]},
+			})
+		}
+	}
+	return combined
+}
+
+// Canonical string form of a tree, e.g. "Mud(Earth,Water)". Trees built by
+// the enumerator are already in canonical child order; other trees are
+// normalized here so equal trees always give equal keys.
+func CanonicalKey(node *model.TreeNode) string {
+	if node == nil {
+		return ""
+	}
+	if len(node.Children) == 0 {
+		return node.Name
+	}
+	keys := make([]string, 0, len(node.Children))
+	for _, child := range node.Children {
+		keys = append(keys, CanonicalKey(child))
+	}
+	if len(keys) == 2 && keys[0] > keys[1] {
+		keys[0], keys[1] = keys[1], keys[0]
+	}
+	return node.Name + "(" + strings.Join(keys, ",") + ")"
+}
+
+// Flatten a tree into a path, ingredients before results. A recipe used in
+// several branches is listed once.
+func FlattenTree(node *model.TreeNode) []model.Recipe {
+	path := []model.Recipe{}
+	seen := make(map[model.Recipe]bool)
+	var visit func(n *model.TreeNode)
+	visit = func(n *model.TreeNode) {
+		if n == nil || n.Recipe == nil {
+			return
+		}
+		for _, child := range n.Children {
+			visit(child)
+		}
+		if !seen[*n.Recipe] {
+			seen[*n.Recipe] = true
+			path = append(path, *n.Recipe)
+		}
+	}
+	visit(node)
+	return path
+}
+
+func EnumerateTrees(ctx context.Context, db *model.ElementsDatabase, startElements []string, targetElement string,
+	maxTrees int, result chan<- *EnumerateResult) {
+
+	defer close(result)
+
+	if maxTrees < 1 {
+		maxTrees = 1
+	}
+	en := &treeEnumerator{
+		ctx:   ctx,
+		table: newRecipeTable(db, startElements),
+		limit: maxTrees,
+		memo:  make(map[string][]*model.TreeNode),
+	}
+
+	trees := []*model.TreeNode{}
+	if _, exists := db.Elements[targetElement]; exists {
+		trees = en.trees(targetElement)
+	}
+
+	paths := make([][]model.Recipe, 0, len(trees))
+	for _, tree := range trees {
+		paths = append(paths, FlattenTree(tree))
+	}
+
+	result <- &EnumerateResult{
+		TargetElement: targetElement,
+		Trees:         trees,
+		Paths:         paths,
+		VisitedNodes:  en.visited,
+	}
+}
+
+func EnumerateDriver(ctx context.Context, db *model.ElementsDatabase, startElements []string, targetElement string, maxTrees int) *EnumerateResult {
+	result := make(chan *EnumerateResult, 1)
+	go EnumerateTrees(ctx, db, normalizeStartElements(startElements), targetElement, maxTrees, result)
+	return <-result
+}
@@ -0,0 +1,58 @@
+package algorithm
+
+import (
+	"context"
+	"shared/utility"
+	"testing"
+)
+
+func TestEnumerateTinyDatabase(t *testing.T) {
+	db := tinyDatabase()
+	tests := []struct {
+		target   string
+		maxTrees int
+		want     int
+	}{
+		{"Mud", 10, 1},
+		{"Brick", 10, 2},
+		{"Wall", 10, 5},
+		{"Wall", 4, 4},
+		{"Water", 10, 1}, // owned: the empty tree
+	}
+	for _, tt := range tests {
+		res := EnumerateDriver(context.Background(), db, nil, tt.target, tt.maxTrees)
+		if len(res.Trees) != tt.want || len(res.Paths) != tt.want {
+			t.Errorf("%s max %d: got %d trees / %d paths, want %d", tt.target, tt.maxTrees, len(res.Trees), len(res.Paths), tt.want)
+		}
+	}
+}
+
+// ENUM trees are distinct, complete, and never more than CountRecipeTrees says exist.
+func TestEnumerateDistinctAndCounted(t *testing.T) {
+	db := loadGameDatabase(t)
+	start := utility.DefaultStartElements
+	counts := CountRecipeTrees(db, start)
+
+	for _, target := range []string{"Mud", "Brick", "Human", "Bread", "Life", "Pizza"} {
+		res := EnumerateDriver(context.Background(), db, start, target, 25)
+		if len(res.Trees) == 0 {
+			t.Errorf("%s: no trees", target)
+			continue
+		}
+		if int64(len(res.Trees)) > counts[target].Int64() && counts[target].IsInt64() {
+			t.Errorf("%s: %d trees but CountRecipeTrees says %s", target, len(res.Trees), counts[target])
+		}
+
+		keys := make(map[string]bool, len(res.Trees))
+		for i, tree := range res.Trees {
+			key := CanonicalKey(tree)
+			if keys[key] {
+				t.Errorf("%s: tree %d is a duplicate: %s", target, i, key)
+			}
+			keys[key] = true
+			if !isCompletePath(res.Paths[i], start) {
+				t.Errorf("%s: path %d is incomplete: %v", target, i, res.Paths[i])
+			}
+		}
+	}
+}
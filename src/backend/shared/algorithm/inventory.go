@@ -0,0 +1,151 @@
+package algorithm
+
+import (
+	"shared/model"
+	"shared/utility"
+	"sort"
+)
+
+// Use the default inventory when none is given and drop duplicate names.
+func normalizeStartElements(startElements []string) []string {
+	if len(startElements) == 0 {
+		startElements = utility.DefaultStartElements
+	}
+	seen := make(map[string]bool, len(startElements))
+	normalized := make([]string, 0, len(startElements))
+	for _, elem := range startElements {
+		if !seen[elem] {
+			seen[elem] = true
+			normalized = append(normalized, elem)
+		}
+	}
+	return normalized
+}
+
+// Every element that can be made from the inventory through recipes the
+// strategy allows (nil: tier-valid recipes), including the inventory itself.
+func reachableFrom(db *model.ElementsDatabase, startElements []string, strategy *SearchStrategy) map[string]bool {
+	ranks := reachableRanks(db, startElements, strategy)
+	reachable := make(map[string]bool, len(ranks))
+	for elem := range ranks {
+		reachable[elem] = true
+	}
+	return reachable
+}
+
+// Same closure, with the round in which every element was first made (0 for
+// the inventory). Ingredients always have a lower rank than what they first
+// make, which keeps relaxed-tier expansion free of cycles.
+func reachableRanks(db *model.ElementsDatabase, startElements []string, strategy *SearchStrategy) map[string]int {
+	ranks := make(map[string]int)
+	frontier := []string{}
+	for _, elem := range strategy.ownedElements(startElements) {
+		if _, ok := ranks[elem]; !ok {
+			ranks[elem] = 0
+			frontier = append(frontier, elem)
+		}
+	}
+
+	for round := 1; len(frontier) > 0; round++ {
+		next := []string{}
+		for _, elem := range frontier {
+			for _, recipe := range utility.UsesOf(db, elem) {
+				_, made := ranks[recipe.Result]
+				r1, ok1 := ranks[recipe.Element1]
+				r2, ok2 := ranks[recipe.Element2]
+				if made || !ok1 || !ok2 || r1 >= round || r2 >= round {
+					continue
+				}
+				resultElement, ok := db.Elements[recipe.Result]
+				if !ok || !strategy.allowsRecipe(recipe) || !strategy.allowsTier(recipe, resultElement, db) {
+					continue
+				}
+				ranks[recipe.Result] = round
+				next = append(next, recipe.Result)
+			}
+		}
+		frontier = next
+	}
+
+	return ranks
+}
+
+// Whether the element is part of the inventory itself. Searches return no
+// paths for such a target, there is nothing to make.
+func inInventory(startElements []string, element string) bool {
+	for _, elem := range startElements {
+		if elem == element {
+			return true
+		}
+	}
+	return false
+}
+
+// A path is complete when every ingredient is owned or made by an earlier step.
+func isCompletePath(path []model.Recipe, startElements []string) bool {
+	made := make(map[string]bool, len(startElements)+len(path))
+	for _, elem := range startElements {
+		made[elem] = true
+	}
+	for _, recipe := range path {
+		if !made[recipe.Element1] || !made[recipe.Element2] {
+			return false
+		}
+		made[recipe.Result] = true
+	}
+	return true
+}
+
+// Tier-valid recipes of every element reachable from an inventory, with each
+// ingredient pair listed once and Result filled in.
+type recipeTable struct {
+	owned   map[string]bool
+	tier    map[string]int
+	recipes map[string][]model.Recipe
+	order   []string // reachable elements that are not owned, by tier then name
+}
+
+func newRecipeTable(db *model.ElementsDatabase, startElements []string) *recipeTable {
+	t := &recipeTable{
+		owned:   make(map[string]bool),
+		tier:    make(map[string]int),
+		recipes: make(map[string][]model.Recipe),
+	}
+	for _, elem := range startElements {
+		t.owned[elem] = true
+	}
+
+	reachable := reachableFrom(db, startElements, nil)
+	for elem := range reachable {
+		t.tier[elem] = utility.ParseTier(db.Elements[elem].Tier)
+		if !t.owned[elem] {
+			t.order = append(t.order, elem)
+		}
+	}
+	//Ingredients always have a lower tier, so this is a topological order
+	sort.Slice(t.order, func(i, j int) bool {
+		if t.tier[t.order[i]] != t.tier[t.order[j]] {
+			return t.tier[t.order[i]] < t.tier[t.order[j]]
+		}
+		return t.order[i] < t.order[j]
+	})
+
+	for _, elem := range t.order {
+		element := db.Elements[elem]
+		seen := make(map[string]bool)
+		usable := []model.Recipe{}
+		for _, recipe := range element.Recipes {
+			key := utility.CombinationKey(recipe.Element1, recipe.Element2)
+			if seen[key] || !reachable[recipe.Element1] || !reachable[recipe.Element2] ||
+				!isValidTierProgression(recipe, element, db) {
+				continue
+			}
+			seen[key] = true
+			recipe.Result = elem
+			usable = append(usable, recipe)
+		}
+		t.recipes[elem] = usable
+	}
+
+	return t
+}
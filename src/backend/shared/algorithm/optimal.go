@@ -0,0 +1,294 @@
+package algorithm
+
+import (
+	"context"
+	"log"
+	"shared/model"
+	"sort"
+)
+
+// Upper limit on branch-and-bound expansions for one target
+const optimalExpansionBudget = 200000
+
+type OptimalResult struct {
+	TargetElement string           `json:"target_element"`
+	Paths         [][]model.Recipe `json:"recipes"`
+	VisitedNodes  int              `json:"visited_nodes"`
+	Combinations  int              `json:"combinations"` // distinct combinations in the best tree
+	TotalCost     float64          `json:"total_cost"`   // sum of the element costs, equal to Combinations without weights
+	Optimal       bool             `json:"optimal"`      // false if the budget ran out before the proof
+}
+
+// The recipe graph as an AND-OR graph: an element (OR node) is made by any one
+// of its recipes, a recipe (AND node) needs both ingredients. The solver picks
+// one recipe per needed element so that the total cost of the distinct
+// elements made is minimal. Without a cost model every element costs 1, which
+// minimizes the number of combinations.
+type optimalSolver struct {
+	owned   map[string]bool
+	recipes map[string][]model.Recipe // usable recipes, cheapest tree first
+	tier    map[string]int
+	cost    map[string]float64 // cost of making the element once
+	depth   map[string]int     // fewest combinations on the longest chain
+	chain   map[string]float64 // cheapest longest chain by cost: a lower bound
+
+	chosen      map[string]model.Recipe
+	chosenCost  float64
+	pending     map[string]bool
+	pendingCost float64
+
+	best     map[string]model.Recipe
+	bestCost float64
+
+	budget    int // expansions allowed per target
+	expanded  int
+	exhausted bool
+	ctx       context.Context
+	progress  chan<- *SearchProgress
+}
+
+func newOptimalSolver(db *model.ElementsDatabase, startElements []string, costs *CostModel) *optimalSolver {
+	table := newRecipeTable(db, startElements)
+	s := &optimalSolver{
+		owned:   table.owned,
+		recipes: make(map[string][]model.Recipe),
+		tier:    table.tier,
+		cost:    make(map[string]float64),
+		depth:   make(map[string]int),
+		chain:   make(map[string]float64),
+		budget:  optimalExpansionBudget,
+	}
+
+	treeCost := make(map[string]float64)
+	for _, elem := range table.order {
+		s.cost[elem] = costs.elementCost(db, elem)
+		usable := append([]model.Recipe{}, table.recipes[elem]...)
+		sort.SliceStable(usable, func(i, j int) bool {
+			return treeCost[usable[i].Element1]+treeCost[usable[i].Element2] <
+				treeCost[usable[j].Element1]+treeCost[usable[j].Element2]
+		})
+
+		s.recipes[elem] = usable
+		if len(usable) > 0 {
+			first := usable[0]
+			treeCost[elem] = s.cost[elem] + treeCost[first.Element1] + treeCost[first.Element2]
+			s.depth[elem] = 1 + max(s.depth[first.Element1], s.depth[first.Element2])
+			s.chain[elem] = s.cost[elem] + max(s.chain[first.Element1], s.chain[first.Element2])
+			for _, recipe := range usable[1:] {
+				s.depth[elem] = min(s.depth[elem], 1+max(s.depth[recipe.Element1], s.depth[recipe.Element2]))
+				if chain := s.cost[elem] + max(s.chain[recipe.Element1], s.chain[recipe.Element2]); chain < s.chain[elem] {
+					s.chain[elem] = chain
+				}
+			}
+		}
+	}
+
+	return s
+}
+
+func (s *optimalSolver) treeCost(tree map[string]model.Recipe) float64 {
+	total := 0.0
+	for elem := range tree {
+		total += s.cost[elem]
+	}
+	return total
+}
+
+// Greedy tree using the cheapest recipe of every element, used as the
+// starting upper bound.
+func (s *optimalSolver) greedy(targetElement string) map[string]model.Recipe {
+	tree := make(map[string]model.Recipe)
+	var add func(elem string)
+	add = func(elem string) {
+		if s.owned[elem] {
+			return
+		}
+		if _, done := tree[elem]; done {
+			return
+		}
+		recipe := s.recipes[elem][0]
+		tree[elem] = recipe
+		add(recipe.Element1)
+		add(recipe.Element2)
+	}
+	add(targetElement)
+	return tree
+}
+
+// Every pending element still has to be made, and so does the cheapest chain
+// below each of them.
+func (s *optimalSolver) lowerBound() float64 {
+	bound := s.pendingCost
+	for elem := range s.pending {
+		bound = max(bound, s.chain[elem])
+	}
+	return s.chosenCost + bound
+}
+
+// Pending element with the highest tier; its ingredients can then never be an
+// element that is already chosen.
+func (s *optimalSolver) nextPending() string {
+	next := ""
+	for elem := range s.pending {
+		if next == "" || s.tier[elem] > s.tier[next] || (s.tier[elem] == s.tier[next] && elem < next) {
+			next = elem
+		}
+	}
+	return next
+}
+
+// Branch and bound for one target, starting from the greedy tree. The state is
+// reset first, so one solver can answer several targets.
+func (s *optimalSolver) solve(ctx context.Context, targetElement string) {
+	s.best = s.greedy(targetElement)
+	s.bestCost = s.treeCost(s.best)
+	s.chosen = make(map[string]model.Recipe)
+	s.chosenCost = 0
+	s.pending = map[string]bool{targetElement: true}
+	s.pendingCost = s.cost[targetElement]
+	s.expanded = 0
+	s.exhausted = false
+	s.ctx = ctx
+	s.search()
+}
+
+func (s *optimalSolver) search() {
+	if len(s.pending) == 0 {
+		if s.chosenCost < s.bestCost {
+			s.best = make(map[string]model.Recipe, len(s.chosen))
+			for elem, recipe := range s.chosen {
+				s.best[elem] = recipe
+			}
+			s.bestCost = s.chosenCost
+		}
+		return
+	}
+	if s.lowerBound() >= s.bestCost {
+		return
+	}
+	if s.expanded >= s.budget || s.ctx.Err() != nil {
+		s.exhausted = true
+		return
+	}
+	s.expanded++
+
+	elem := s.nextPending()
+	if s.progress != nil {
+		select {
+		case s.progress <- &SearchProgress{
+			CurrentElement: elem,
+			Visited:        s.expanded,
+			PathsFound:     0,
+		}:
+		default:
+		}
+	}
+
+	delete(s.pending, elem)
+	s.pendingCost -= s.cost[elem]
+	s.chosenCost += s.cost[elem]
+	for _, recipe := range s.recipes[elem] {
+		s.chosen[elem] = recipe
+		added := []string{}
+		for _, ingredient := range []string{recipe.Element1, recipe.Element2} {
+			if s.owned[ingredient] || s.pending[ingredient] {
+				continue
+			}
+			if _, done := s.chosen[ingredient]; done {
+				continue
+			}
+			s.pending[ingredient] = true
+			s.pendingCost += s.cost[ingredient]
+			added = append(added, ingredient)
+		}
+
+		s.search()
+
+		for _, ingredient := range added {
+			delete(s.pending, ingredient)
+			s.pendingCost -= s.cost[ingredient]
+		}
+		delete(s.chosen, elem)
+	}
+	s.chosenCost -= s.cost[elem]
+	s.pending[elem] = true
+	s.pendingCost += s.cost[elem]
+}
+
+// Order the chosen recipes so every ingredient is made before it is used.
+func orderTree(tree map[string]model.Recipe, targetElement string) []model.Recipe {
+	path := []model.Recipe{}
+	done := make(map[string]bool)
+	var visit func(elem string)
+	visit = func(elem string) {
+		recipe, ok := tree[elem]
+		if !ok || done[elem] {
+			return
+		}
+		done[elem] = true
+		visit(recipe.Element1)
+		visit(recipe.Element2)
+		path = append(path, recipe)
+	}
+	visit(targetElement)
+	return path
+}
+
+func OptimalSearch(ctx context.Context, db *model.ElementsDatabase, startElements []string, targetElement string,
+	result chan<- *OptimalResult, progress chan<- *SearchProgress) {
+	WeightedSearch(ctx, db, startElements, targetElement, nil, result, progress)
+}
+
+// Recipe tree of the target with the minimum total cost under the cost model.
+func WeightedSearch(ctx context.Context, db *model.ElementsDatabase, startElements []string, targetElement string,
+	costs *CostModel, result chan<- *OptimalResult, progress chan<- *SearchProgress) {
+
+	defer close(result)
+
+	solver := newOptimalSolver(db, startElements, costs)
+	//An owned target has no path to find, the same as for BFS
+	if solver.owned[targetElement] {
+		result <- &OptimalResult{
+			TargetElement: targetElement,
+			Paths:         [][]model.Recipe{},
+			Optimal:       true,
+		}
+		return
+	}
+	if len(solver.recipes[targetElement]) == 0 {
+		result <- &OptimalResult{
+			TargetElement: targetElement,
+			Paths:         [][]model.Recipe{},
+			Optimal:       true,
+		}
+		return
+	}
+
+	solver.progress = progress
+	solver.solve(ctx, targetElement)
+
+	log.Printf("Optimal: %s needs %d combinations, cost %g (%d expansions, proven: %v)",
+		targetElement, len(solver.best), solver.bestCost, solver.expanded, !solver.exhausted)
+
+	result <- &OptimalResult{
+		TargetElement: targetElement,
+		Paths:         [][]model.Recipe{orderTree(solver.best, targetElement)},
+		VisitedNodes:  solver.expanded,
+		Combinations:  len(solver.best),
+		TotalCost:     solver.bestCost,
+		Optimal:       !solver.exhausted,
+	}
+}
+
+func OptimalDriver(ctx context.Context, db *model.ElementsDatabase, startElements []string, targetElement string, step chan<- *SearchProgress) *OptimalResult {
+	result := make(chan *OptimalResult, 1)
+	go OptimalSearch(ctx, db, normalizeStartElements(startElements), targetElement, result, step)
+	return <-result
+}
+
+func WeightedDriver(ctx context.Context, db *model.ElementsDatabase, startElements []string, targetElement string,
+	costs *CostModel, step chan<- *SearchProgress) *OptimalResult {
+	result := make(chan *OptimalResult, 1)
+	go WeightedSearch(ctx, db, normalizeStartElements(startElements), targetElement, costs, result, step)
+	return <-result
+}
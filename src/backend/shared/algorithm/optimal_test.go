@@ -0,0 +1,127 @@
+package algorithm
+
+import (
+	"context"
+	"shared/model"
+	"shared/utility"
+	"sync"
+	"testing"
+)
+
+var (
+	gameDatabase     *model.ElementsDatabase
+	gameDatabaseErr  error
+	gameDatabaseOnce sync.Once
+)
+
+// The real element data, loaded once for all tests.
+func loadGameDatabase(t *testing.T) *model.ElementsDatabase {
+	t.Helper()
+	gameDatabaseOnce.Do(func() {
+		gameDatabase, gameDatabaseErr = utility.LoadElementsFromFile("../data/elements.json")
+	})
+	if gameDatabaseErr != nil {
+		t.Fatalf("load elements: %v", gameDatabaseErr)
+	}
+	return gameDatabase
+}
+
+// Small database with known answers:
+//
+//	Mud   = Water+Earth               (tier 1)
+//	Steam = Water+Fire                (tier 1)
+//	Brick = Mud+Fire | Mud+Steam      (tier 2, 2 trees)
+//	Wall  = Brick+Brick | Brick+Mud   (tier 3, 3 + 2*1 = 5 trees; Brick+Brick pairs are unordered)
+func tinyDatabase() *model.ElementsDatabase {
+	db := &model.ElementsDatabase{Elements: map[string]model.Element{}}
+	add := func(name, tier string, recipes ...[2]string) {
+		element := model.Element{ID: name, Name: name, Tier: tier, IsBasic: tier == "Starting elements"}
+		for _, pair := range recipes {
+			element.Recipes = append(element.Recipes, model.Recipe{Element1: pair[0], Element2: pair[1], Result: name})
+		}
+		db.Elements[name] = element
+	}
+	for _, basic := range utility.DefaultStartElements {
+		add(basic, "Starting elements")
+	}
+	add("Mud", "Tier 1 elements", [2]string{"Water", "Earth"})
+	add("Steam", "Tier 1 elements", [2]string{"Water", "Fire"})
+	add("Brick", "Tier 2 elements", [2]string{"Mud", "Fire"}, [2]string{"Mud", "Steam"})
+	add("Wall", "Tier 3 elements", [2]string{"Brick", "Brick"}, [2]string{"Brick", "Mud"})
+	db.Order = utility.TierOrder(db)
+	utility.BuildIndex(db)
+	return db
+}
+
+// Number of distinct elements a path makes. Any path that makes a set of
+// elements contains a recipe tree using at most that many combinations.
+func madeElements(path []model.Recipe) int {
+	made := make(map[string]bool, len(path))
+	for _, recipe := range path {
+		made[recipe.Result] = true
+	}
+	return len(made)
+}
+
+func TestOptimalTinyDatabase(t *testing.T) {
+	db := tinyDatabase()
+	tests := []struct {
+		target       string
+		combinations int
+	}{
+		{"Mud", 1},
+		{"Brick", 2},
+		{"Wall", 3},
+	}
+	for _, tt := range tests {
+		res := OptimalDriver(context.Background(), db, nil, tt.target, nil)
+		if !res.Optimal || len(res.Paths) != 1 {
+			t.Fatalf("%s: optimal=%v paths=%d", tt.target, res.Optimal, len(res.Paths))
+		}
+		if res.Combinations != tt.combinations || len(res.Paths[0]) != tt.combinations {
+			t.Errorf("%s: got %d combinations (%d steps), want %d", tt.target, res.Combinations, len(res.Paths[0]), tt.combinations)
+		}
+		if !isCompletePath(res.Paths[0], utility.DefaultStartElements) {
+			t.Errorf("%s: incomplete path %v", tt.target, res.Paths[0])
+		}
+	}
+}
+
+// OPTIMAL, once proven, is never worse than what BFS, DFS or ENUM return.
+func TestOptimalNotWorseThanOtherMethods(t *testing.T) {
+	db := loadGameDatabase(t)
+	ctx := context.Background()
+	start := utility.DefaultStartElements
+
+	for _, target := range []string{"Mud", "Brick", "Human", "Bread", "Life", "Wall"} {
+		optimal := OptimalDriver(ctx, db, start, target, nil)
+		if len(optimal.Paths) != 1 {
+			t.Fatalf("%s: OPTIMAL found %d paths", target, len(optimal.Paths))
+		}
+		if !isCompletePath(optimal.Paths[0], start) {
+			t.Errorf("%s: OPTIMAL path is incomplete: %v", target, optimal.Paths[0])
+		}
+		if !optimal.Optimal {
+			continue
+		}
+
+		others := map[string][][]model.Recipe{
+			"BFS":  Driver(ctx, db, start, target, 1, nil, nil).Paths,
+			"DFS":  MultiDFS(ctx, db, start, target, 3, &SearchStrategy{Type: "DFS"}, nil).Paths,
+			"ENUM": EnumerateDriver(ctx, db, start, target, 5).Paths,
+		}
+		for method, paths := range others {
+			if len(paths) == 0 {
+				t.Errorf("%s: %s found nothing", target, method)
+			}
+			for _, path := range paths {
+				if !isCompletePath(path, start) {
+					t.Errorf("%s: %s path is incomplete: %v", target, method, path)
+				}
+				if made := madeElements(path); made < optimal.Combinations {
+					t.Errorf("%s: %s path makes %d elements, OPTIMAL claims %d is minimal", target, method, made, optimal.Combinations)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package algorithm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"shared/model"
+	"shared/utility"
+	"sort"
+)
+
+type PlanResult struct {
+	Targets       []string       `json:"targets"`
+	Steps         []model.Recipe `json:"steps"`         // one plan that makes every target
+	Missing       []string       `json:"missing"`       // targets no recipe was found for
+	SeparateSteps int            `json:"separateSteps"` // total steps when every target is solved on its own
+	StepsSaved    int            `json:"stepsSaved"`
+	VisitedNodes  int            `json:"visited_nodes"`
+}
+
+// Combined crafting plan for several targets. Targets are solved with Driver
+// from lowest tier up, each search starting from the inventory plus everything
+// the plan already makes, so shared intermediates are made once. The plain
+// union of the separate recipes is kept instead when it happens to be shorter.
+func PlanTargets(ctx context.Context, db *model.ElementsDatabase, startElements []string, targets []string,
+	strategy *SearchStrategy) (*PlanResult, error) {
+
+	startElements = normalizeStartElements(startElements)
+	targets = uniqueTargets(targets)
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("targets must not be empty")
+	}
+
+	ordered := append([]string{}, targets...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return utility.ParseTier(db.Elements[ordered[i]].Tier) < utility.ParseTier(db.Elements[ordered[j]].Tier)
+	})
+
+	result := &PlanResult{
+		Targets: targets,
+		Steps:   []model.Recipe{},
+		Missing: []string{},
+	}
+
+	separate := []model.Recipe{}
+	combined := newPlanBuilder(startElements)
+	for _, target := range ordered {
+		if ctx.Err() != nil {
+			break
+		}
+
+		alone := Driver(ctx, db, startElements, target, 1, strategy, nil)
+		result.VisitedNodes += alone.VisitedNodes
+		if len(alone.Paths) == 0 {
+			result.Missing = append(result.Missing, target)
+			continue
+		}
+		result.SeparateSteps += len(alone.Paths[0])
+		separate = append(separate, alone.Paths[0]...)
+
+		//Everything made so far counts as owned for the next target
+		reused := Driver(ctx, db, combined.inventory(), target, 1, strategy, nil)
+		result.VisitedNodes += reused.VisitedNodes
+		if len(reused.Paths) > 0 {
+			combined.add(reused.Paths[0])
+		} else {
+			combined.add(alone.Paths[0])
+		}
+	}
+
+	union := newPlanBuilder(startElements)
+	union.add(separate)
+
+	result.Steps = combined.steps
+	if len(union.steps) < len(combined.steps) {
+		result.Steps = union.steps
+	}
+	result.StepsSaved = result.SeparateSteps - len(result.Steps)
+
+	log.Printf("Plan for %v: %d steps, %d when solved separately", targets, len(result.Steps), result.SeparateSteps)
+	return result, nil
+}
+
+// Targets without duplicates, in request order. Unlike the inventory an empty
+// list stays empty.
+func uniqueTargets(targets []string) []string {
+	seen := make(map[string]bool, len(targets))
+	unique := make([]string, 0, len(targets))
+	for _, target := range targets {
+		if !seen[target] {
+			seen[target] = true
+			unique = append(unique, target)
+		}
+	}
+	return unique
+}
+
+// Steps of a plan, each element made at most once.
+type planBuilder struct {
+	owned []string
+	made  map[string]bool
+	steps []model.Recipe
+}
+
+func newPlanBuilder(startElements []string) *planBuilder {
+	b := &planBuilder{
+		owned: startElements,
+		made:  make(map[string]bool, len(startElements)),
+		steps: []model.Recipe{},
+	}
+	for _, elem := range startElements {
+		b.made[elem] = true
+	}
+	return b
+}
+
+func (b *planBuilder) add(path []model.Recipe) {
+	for _, recipe := range path {
+		if b.made[recipe.Result] {
+			continue
+		}
+		b.made[recipe.Result] = true
+		b.steps = append(b.steps, recipe)
+	}
+}
+
+// Inventory plus made elements, owned ones first and the rest in plan order.
+func (b *planBuilder) inventory() []string {
+	inventory := append([]string{}, b.owned...)
+	for _, recipe := range b.steps {
+		inventory = append(inventory, recipe.Result)
+	}
+	return inventory
+}
@@ -0,0 +1,31 @@
+package algorithm
+
+import (
+	"context"
+	"reflect"
+	"shared/utility"
+	"testing"
+)
+
+func TestPlanTargets(t *testing.T) {
+	db := tinyDatabase()
+	ctx := context.Background()
+
+	plan, err := PlanTargets(ctx, db, nil, []string{"Wall", "Brick", "Wall"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Wall", "Brick"}; !reflect.DeepEqual(plan.Targets, want) {
+		t.Errorf("targets %v, want %v", plan.Targets, want)
+	}
+	if len(plan.Missing) != 0 || !isCompletePath(plan.Steps, utility.DefaultStartElements) || madeElements(plan.Steps) != 3 {
+		t.Errorf("plan %v, missing %v; want Mud, Brick and Wall made once each", plan.Steps, plan.Missing)
+	}
+
+	// Target kosong tidak boleh diganti elemen dasar
+	for _, targets := range [][]string{nil, {}} {
+		if plan, err := PlanTargets(ctx, db, nil, targets, nil); err == nil {
+			t.Errorf("targets %v: got plan for %v, want error", targets, plan.Targets)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package algorithm
+
+import (
+	"fmt"
+	"shared/model"
+	"shared/utility"
+	"sort"
+	"strings"
+)
+
+// Orders for multiple results. Without one, results keep the order the
+// search found them in.
+const (
+	SortDiverse = "diverse" // each next result as different as possible from the ones already picked
+	SortSteps   = "steps"   // fewest steps first
+	SortMaxTier = "tier"    // lowest highest intermediate tier first
+)
+
+// How many candidates a search collects per requested result when ranking,
+// so the ranking has something to choose from.
+const RankCandidateFactor = 3
+
+func ParseSort(value string) (string, error) {
+	switch sortBy := strings.ToLower(value); sortBy {
+	case "", SortDiverse, SortSteps, SortMaxTier:
+		return sortBy, nil
+	default:
+		return "", fmt.Errorf("unknown sort %q (use %s, %s or %s)", value, SortDiverse, SortSteps, SortMaxTier)
+	}
+}
+
+// Pick at most n paths in the requested order. Ties keep the search order.
+func RankPaths(db *model.ElementsDatabase, paths [][]model.Recipe, targetElement string, sortBy string, n int) [][]model.Recipe {
+	ranked := append([][]model.Recipe{}, paths...)
+
+	switch sortBy {
+	case SortSteps:
+		sort.SliceStable(ranked, func(i, j int) bool {
+			return len(ranked[i]) < len(ranked[j])
+		})
+	case SortMaxTier:
+		tiers := make([]int, len(ranked))
+		for i, path := range ranked {
+			tiers[i] = maxIntermediateTier(db, path, targetElement)
+		}
+		order := make([]int, len(ranked))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			i, j := order[a], order[b]
+			if tiers[i] != tiers[j] {
+				return tiers[i] < tiers[j]
+			}
+			return len(ranked[i]) < len(ranked[j])
+		})
+		sorted := make([][]model.Recipe, len(ranked))
+		for a, i := range order {
+			sorted[a] = ranked[i]
+		}
+		ranked = sorted
+	case SortDiverse:
+		ranked = mostDiverse(ranked, n)
+	}
+
+	if n > 0 && len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
+
+func maxIntermediateTier(db *model.ElementsDatabase, path []model.Recipe, targetElement string) int {
+	highest := 0
+	for _, recipe := range path {
+		if recipe.Result != targetElement {
+			highest = max(highest, utility.ParseTier(db.Elements[recipe.Result].Tier))
+		}
+	}
+	return highest
+}
+
+// Greedy max-min selection: start with the shortest path, then keep adding the
+// path whose smallest distance to the picked ones is largest.
+func mostDiverse(paths [][]model.Recipe, n int) [][]model.Recipe {
+	if len(paths) == 0 {
+		return paths
+	}
+	if n <= 0 || n > len(paths) {
+		n = len(paths)
+	}
+
+	sets := make([]map[string]bool, len(paths))
+	for i, path := range paths {
+		sets[i] = make(map[string]bool, len(path))
+		for _, recipe := range path {
+			sets[i][utility.CombinationKey(recipe.Element1, recipe.Element2)+"->"+recipe.Result] = true
+		}
+	}
+
+	first := 0
+	for i := range paths {
+		if len(paths[i]) < len(paths[first]) {
+			first = i
+		}
+	}
+	picked := []int{first}
+	used := map[int]bool{first: true}
+
+	//Smallest distance from every candidate to the picked paths
+	nearest := make([]float64, len(paths))
+	for i := range paths {
+		nearest[i] = recipeSetDistance(sets[i], sets[first])
+	}
+
+	for len(picked) < n {
+		next := -1
+		for i := range paths {
+			if used[i] {
+				continue
+			}
+			if next < 0 || nearest[i] > nearest[next] ||
+				(nearest[i] == nearest[next] && len(paths[i]) < len(paths[next])) {
+				next = i
+			}
+		}
+		picked = append(picked, next)
+		used[next] = true
+		for i := range paths {
+			if distance := recipeSetDistance(sets[i], sets[next]); distance < nearest[i] {
+				nearest[i] = distance
+			}
+		}
+	}
+
+	diverse := make([][]model.Recipe, 0, len(picked))
+	for _, i := range picked {
+		diverse = append(diverse, paths[i])
+	}
+	return diverse
+}
+
+// Jaccard distance between two recipe sets: 0 when equal, 1 when disjoint.
+func recipeSetDistance(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for key := range a {
+		if b[key] {
+			shared++
+		}
+	}
+	return 1 - float64(shared)/float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,208 @@
+package algorithm
+
+import (
+	"context"
+	"fmt"
+	"shared/model"
+	"sort"
+	"strings"
+)
+
+// Why an element cannot be made from an inventory
+const (
+	ReasonMissing            = "missing-from-database" // the name is not an element at all
+	ReasonNoRecipes          = "no-recipes"            // nothing combines into it
+	ReasonTierInvalid        = "tier-invalid-only"     // every way to make it breaks the tier rule
+	ReasonMissingIngredients = "missing-ingredients"   // every recipe needs something that cannot be made
+)
+
+// Expansions the OPTIMAL solver may spend on each element of a reachability
+// report; elements it cannot prove within this keep the best tree found.
+const reachabilityExpansionBudget = 2000
+
+type ReachableElement struct {
+	Name         string `json:"name"`
+	Tier         string `json:"tier"`
+	Combinations int    `json:"combinations"` // minimum number of combinations, 0 when owned
+	Proven       bool   `json:"proven"`       // false: the budget ran out, Combinations is the best found (an upper bound)
+	Depth        int    `json:"depth"`        // fewest combinations on the longest chain, a lower bound
+}
+
+type UnreachableElement struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+	Detail string `json:"detail"`
+}
+
+type ReachabilityReport struct {
+	Owned       []string             `json:"owned"`
+	Reachable   []ReachableElement   `json:"reachable"`   // owned first, then by tier and name
+	Unreachable []UnreachableElement `json:"unreachable"` // by name
+}
+
+// Closure of an inventory under tier-valid recipes, the same rule the searches
+// use. Elements outside it are listed with the reason; names that recipes
+// mention but the database lacks are listed as well.
+func AnalyzeReachability(db *model.ElementsDatabase, startElements []string) *ReachabilityReport {
+	startElements = normalizeStartElements(startElements)
+	solver := newOptimalSolver(db, startElements, nil)
+
+	report := &ReachabilityReport{
+		Owned:       startElements,
+		Reachable:   []ReachableElement{},
+		Unreachable: []UnreachableElement{},
+	}
+	for _, elem := range startElements {
+		report.Reachable = append(report.Reachable, ReachableElement{
+			Name:   elem,
+			Tier:   db.Elements[elem].Tier,
+			Proven: true,
+		})
+	}
+	//Tier then name, the order newRecipeTable uses
+	made := make([]string, 0, len(solver.recipes))
+	for elem := range solver.recipes {
+		made = append(made, elem)
+	}
+	sort.Slice(made, func(i, j int) bool {
+		if solver.tier[made[i]] != solver.tier[made[j]] {
+			return solver.tier[made[i]] < solver.tier[made[j]]
+		}
+		return made[i] < made[j]
+	})
+	solver.budget = reachabilityExpansionBudget
+	for _, elem := range made {
+		solver.solve(context.Background(), elem)
+		report.Reachable = append(report.Reachable, ReachableElement{
+			Name:         elem,
+			Tier:         db.Elements[elem].Tier,
+			Combinations: len(solver.best),
+			Proven:       !solver.exhausted,
+			Depth:        solver.depth[elem],
+		})
+	}
+
+	explainer := newUnreachableExplainer(db, startElements)
+	names := []string{}
+	for name := range db.Elements {
+		if !explainer.reachable[name] {
+			names = append(names, name)
+		}
+	}
+	for name := range explainer.missingNames() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		report.Unreachable = append(report.Unreachable, explainer.explain(name))
+	}
+
+	return report
+}
+
+// Reason the target cannot be made from the inventory, or nil if it can.
+func ExplainUnreachable(db *model.ElementsDatabase, startElements []string, targetElement string) *UnreachableElement {
+	explainer := newUnreachableExplainer(db, normalizeStartElements(startElements))
+	if explainer.reachable[targetElement] {
+		return nil
+	}
+	reason := explainer.explain(targetElement)
+	return &reason
+}
+
+// Explanation for a search that returned no recipes.
+func ExplainEmptyResult(db *model.ElementsDatabase, startElements []string, targetElement string, strategy *SearchStrategy) string {
+	if inInventory(normalizeStartElements(startElements), targetElement) {
+		return fmt.Sprintf("%s is already in the starting inventory", targetElement)
+	}
+	if reason := ExplainUnreachable(db, startElements, targetElement); reason != nil {
+		return reason.Detail
+	}
+	if !reachableFrom(db, normalizeStartElements(startElements), strategy)[targetElement] {
+		return fmt.Sprintf("%s cannot be made within the requested exclusions and tier limits", targetElement)
+	}
+	return fmt.Sprintf("%s is reachable, but the search stopped before finding a recipe that fits the request "+
+		"(time or depth limit, required elements, or cancellation)", targetElement)
+}
+
+type unreachableExplainer struct {
+	db        *model.ElementsDatabase
+	reachable map[string]bool // tier-valid recipes only
+	relaxed   map[string]bool // any recipe
+}
+
+func newUnreachableExplainer(db *model.ElementsDatabase, startElements []string) *unreachableExplainer {
+	return &unreachableExplainer{
+		db:        db,
+		reachable: reachableFrom(db, startElements, nil),
+		relaxed:   reachableFrom(db, startElements, &SearchStrategy{RelaxTiers: true}),
+	}
+}
+
+// Ingredient names that recipes use but the database does not contain.
+func (ex *unreachableExplainer) missingNames() map[string]bool {
+	missing := make(map[string]bool)
+	for _, element := range ex.db.Elements {
+		for _, recipe := range element.Recipes {
+			for _, ingredient := range []string{recipe.Element1, recipe.Element2} {
+				if _, ok := ex.db.Elements[ingredient]; !ok {
+					missing[ingredient] = true
+				}
+			}
+		}
+	}
+	return missing
+}
+
+func (ex *unreachableExplainer) explain(name string) UnreachableElement {
+	element, ok := ex.db.Elements[name]
+	if !ok {
+		return UnreachableElement{
+			Name:   name,
+			Reason: ReasonMissing,
+			Detail: fmt.Sprintf("%s is not in the element database", name),
+		}
+	}
+	if len(element.Recipes) == 0 {
+		return UnreachableElement{
+			Name:   name,
+			Reason: ReasonNoRecipes,
+			Detail: fmt.Sprintf("no combination makes %s and it is not in the inventory", name),
+		}
+	}
+
+	if ex.relaxed[name] {
+		for _, recipe := range element.Recipes {
+			if ex.relaxed[recipe.Element1] && ex.relaxed[recipe.Element2] {
+				return UnreachableElement{
+					Name:   name,
+					Reason: ReasonTierInvalid,
+					Detail: fmt.Sprintf("%s (%s) is only reachable when ingredients may have the same or a higher tier, e.g. through %s + %s (%s, %s)",
+						name, element.Tier, recipe.Element1, recipe.Element2,
+						ex.db.Elements[recipe.Element1].Tier, ex.db.Elements[recipe.Element2].Tier),
+				}
+			}
+		}
+	}
+
+	//Ingredients that block every recipe, listed once
+	blocking := make(map[string]bool)
+	for _, recipe := range element.Recipes {
+		for _, ingredient := range []string{recipe.Element1, recipe.Element2} {
+			if !ex.relaxed[ingredient] {
+				blocking[ingredient] = true
+			}
+		}
+	}
+	names := keysFromMap(blocking)
+	for i, ingredient := range names {
+		if _, ok := ex.db.Elements[ingredient]; !ok {
+			names[i] = ingredient + " (not in database)"
+		}
+	}
+	return UnreachableElement{
+		Name:   name,
+		Reason: ReasonMissingIngredients,
+		Detail: fmt.Sprintf("every recipe of %s needs an element that cannot be made: %s", name, strings.Join(names, ", ")),
+	}
+}
@@ -0,0 +1,128 @@
+package algorithm
+
+import (
+	"context"
+	"log"
+	"math"
+	"shared/model"
+	"shared/utility"
+)
+
+type RouteStep struct {
+	Step       int          `json:"step"`
+	Recipe     model.Recipe `json:"recipe"`
+	Tier       string       `json:"tier"`
+	InRange    bool         `json:"inRange"`    // false for prerequisites below the tier range
+	Discovered int          `json:"discovered"` // elements of the range discovered so far
+	Progress   float64      `json:"progress"`   // percentage of the reachable range discovered
+}
+
+type RouteResult struct {
+	MinTier     int         `json:"minTier"`
+	MaxTier     int         `json:"maxTier"` // 0 = no upper limit
+	Targets     int         `json:"targets"` // reachable elements in the range that are not owned
+	Steps       []RouteStep `json:"steps"`
+	Unreachable []string    `json:"unreachable"` // elements in the range no tier-valid recipe reaches
+}
+
+// Ordered combinations that discover every element of a tier range. Targets
+// are visited in utility.TierOrder, so with the full range every ingredient is
+// already known and each element costs exactly one combination, which is the
+// minimum. With a partial range the missing prerequisites are made first along
+// their cheapest recipe trees.
+func PlanCompletion(ctx context.Context, db *model.ElementsDatabase, startElements []string, minTier, maxTier int) *RouteResult {
+	startElements = normalizeStartElements(startElements)
+	table := newRecipeTable(db, startElements)
+
+	inRange := func(elem string) bool {
+		tier := utility.ParseTier(db.Elements[elem].Tier)
+		return tier >= minTier && (maxTier <= 0 || tier <= maxTier)
+	}
+
+	//Size of the cheapest tree of every element, counting repeats
+	treeCost := make(map[string]int)
+	for _, elem := range table.order {
+		for i, recipe := range table.recipes[elem] {
+			cost := 1 + treeCost[recipe.Element1] + treeCost[recipe.Element2]
+			if i == 0 || cost < treeCost[elem] {
+				treeCost[elem] = cost
+			}
+		}
+	}
+
+	result := &RouteResult{
+		MinTier:     minTier,
+		MaxTier:     maxTier,
+		Steps:       []RouteStep{},
+		Unreachable: []string{},
+	}
+	targets := []string{}
+	for _, elem := range utility.TierOrder(db) {
+		if !inRange(elem) || table.owned[elem] {
+			continue
+		}
+		if _, reachable := table.tier[elem]; !reachable {
+			result.Unreachable = append(result.Unreachable, elem)
+			continue
+		}
+		targets = append(targets, elem)
+	}
+	result.Targets = len(targets)
+
+	made := make(map[string]bool, len(table.owned))
+	for elem := range table.owned {
+		made[elem] = true
+	}
+	discovered := 0
+
+	var makeElement func(elem string)
+	makeElement = func(elem string) {
+		if made[elem] {
+			return
+		}
+		//Recipe needing the least work on top of what is already made
+		var best model.Recipe
+		bestCost := -1
+		for _, recipe := range table.recipes[elem] {
+			cost := 0
+			for _, ingredient := range []string{recipe.Element1, recipe.Element2} {
+				if !made[ingredient] {
+					cost += treeCost[ingredient]
+				}
+			}
+			if bestCost < 0 || cost < bestCost {
+				best, bestCost = recipe, cost
+			}
+		}
+		makeElement(best.Element1)
+		makeElement(best.Element2)
+
+		made[elem] = true
+		step := RouteStep{
+			Step:    len(result.Steps) + 1,
+			Recipe:  best,
+			Tier:    db.Elements[elem].Tier,
+			InRange: inRange(elem),
+		}
+		if step.InRange {
+			discovered++
+		}
+		step.Discovered = discovered
+		step.Progress = 100
+		if len(targets) > 0 {
+			step.Progress = math.Round(float64(discovered)*10000/float64(len(targets))) / 100
+		}
+		result.Steps = append(result.Steps, step)
+	}
+
+	for _, elem := range targets {
+		if ctx.Err() != nil {
+			log.Printf("Completion route cancelled: %v", ctx.Err())
+			break
+		}
+		makeElement(elem)
+	}
+
+	log.Printf("Completion route for tiers %d-%d: %d steps for %d elements", minTier, maxTier, len(result.Steps), len(targets))
+	return result
+}
@@ -0,0 +1,167 @@
+package algorithm
+
+import (
+	"shared/model"
+	"shared/utility"
+	"sort"
+	"strings"
+)
+
+// Search options taken from a request.
+func NewSearchStrategy(req model.SearchRequest) *SearchStrategy {
+	strategy := &SearchStrategy{
+		Type:            strings.ToUpper(req.Method),
+		Target:          req.Target,
+		Exclusions:      make(map[string]bool),
+		ExcludedRecipes: make(map[string]bool),
+		MustInclude:     req.MustInclude,
+		MaxDepth:        req.MaxDepth,
+		MaxTier:         req.MaxTier,
+		PreferredTiers:  req.PreferredTiers,
+		RelaxTiers:      req.RelaxTiers,
+	}
+	for _, elem := range req.ExcludeElements {
+		strategy.Exclusions[elem] = true
+	}
+	for _, recipe := range req.ExcludeRecipes {
+		strategy.ExcludedRecipes[excludedRecipeKey(recipe.Element1, recipe.Element2, recipe.Result)] = true
+	}
+	return strategy
+}
+
+// Key of an excluded recipe. An empty result excludes the pair for every result.
+func excludedRecipeKey(e1, e2, result string) string {
+	return utility.CombinationKey(e1, e2) + "->" + result
+}
+
+func (s *SearchStrategy) allowsElement(name string) bool {
+	return s == nil || !s.Exclusions[name]
+}
+
+// A recipe is allowed when none of its elements is excluded and neither the
+// recipe itself nor its ingredient pair is excluded.
+func (s *SearchStrategy) allowsRecipe(recipe model.Recipe) bool {
+	if s == nil {
+		return true
+	}
+	if s.Exclusions[recipe.Element1] || s.Exclusions[recipe.Element2] || s.Exclusions[recipe.Result] {
+		return false
+	}
+	if len(s.ExcludedRecipes) == 0 {
+		return true
+	}
+	return !s.ExcludedRecipes[excludedRecipeKey(recipe.Element1, recipe.Element2, recipe.Result)] &&
+		!s.ExcludedRecipes[excludedRecipeKey(recipe.Element1, recipe.Element2, "")]
+}
+
+// The inventory without excluded elements.
+func (s *SearchStrategy) ownedElements(startElements []string) []string {
+	if s == nil || len(s.Exclusions) == 0 {
+		return startElements
+	}
+	owned := make([]string, 0, len(startElements))
+	for _, elem := range startElements {
+		if !s.Exclusions[elem] {
+			owned = append(owned, elem)
+		}
+	}
+	return owned
+}
+
+func (s *SearchStrategy) hasRequirements() bool {
+	return s != nil && len(s.MustInclude) > 0
+}
+
+// Whether an expanded path uses every must-include element.
+func (s *SearchStrategy) includesRequired(path []model.Recipe) bool {
+	if !s.hasRequirements() {
+		return true
+	}
+	used := make(map[string]bool)
+	for _, recipe := range path {
+		used[recipe.Element1] = true
+		used[recipe.Element2] = true
+		used[recipe.Result] = true
+	}
+	for _, elem := range s.MustInclude {
+		if !used[elem] {
+			return false
+		}
+	}
+	return true
+}
+
+// Copy for sub-searches (filling in a missing ingredient), which keep the
+// exclusions but must not demand the must-include elements themselves. They
+// report no progress, so they must not wait on the step controller either.
+func (s *SearchStrategy) forSubSearch() *SearchStrategy {
+	if s == nil || (!s.hasRequirements() && s.Control == nil) {
+		return s
+	}
+	sub := *s
+	sub.MustInclude = nil
+	sub.Control = nil
+	return &sub
+}
+
+// Tier rule of the search. By default both ingredients must have a lower tier
+// than the result; RelaxTiers drops that rule. MaxTier caps every element that
+// gets made except the target itself.
+func (s *SearchStrategy) allowsTier(recipe model.Recipe, resultElement model.Element, db *model.ElementsDatabase) bool {
+	if s == nil {
+		return isValidTierProgression(recipe, resultElement, db)
+	}
+	if s.MaxTier > 0 && resultElement.ID != s.Target && utility.ParseTier(resultElement.Tier) > s.MaxTier {
+		return false
+	}
+	if s.RelaxTiers {
+		_, ok1 := db.Elements[recipe.Element1]
+		_, ok2 := db.Elements[recipe.Element2]
+		return ok1 && ok2
+	}
+	return isValidTierProgression(recipe, resultElement, db)
+}
+
+func (s *SearchStrategy) controller() *Controller {
+	if s == nil {
+		return nil
+	}
+	return s.Control
+}
+
+func (s *SearchStrategy) pathFound(path []model.Recipe) {
+	if s != nil && s.OnPath != nil {
+		s.OnPath(path)
+	}
+}
+
+func (s *SearchStrategy) relaxed() bool {
+	return s != nil && s.RelaxTiers
+}
+
+// Recipes ordered so the ones touching more preferred tiers come first. The
+// order is otherwise kept, so without PreferredTiers nothing changes.
+func (s *SearchStrategy) preferredFirst(recipes []model.Recipe, db *model.ElementsDatabase) []model.Recipe {
+	if s == nil || len(s.PreferredTiers) == 0 || len(recipes) < 2 {
+		return recipes
+	}
+	preferred := make(map[int]bool, len(s.PreferredTiers))
+	for _, tier := range s.PreferredTiers {
+		preferred[tier] = true
+	}
+	score := func(recipe model.Recipe) int {
+		n := 0
+		for _, elem := range []string{recipe.Element1, recipe.Element2, recipe.Result} {
+			if preferred[utility.ParseTier(db.Elements[elem].Tier)] {
+				n++
+			}
+		}
+		return n
+	}
+
+	ordered := append([]model.Recipe{}, recipes...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return score(ordered[i]) > score(ordered[j])
+	})
+	return ordered
+}
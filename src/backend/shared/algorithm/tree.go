@@ -0,0 +1,52 @@
+package algorithm
+
+import "shared/model"
+
+// Build the recipe tree of targetElement from a flat path. Start elements and
+// elements that no step of the path produces become leaves.
+func BuildTree(path []model.Recipe, targetElement string, startElements []string) *model.TreeNode {
+	isBasic := make(map[string]bool, len(startElements))
+	for _, elem := range startElements {
+		isBasic[elem] = true
+	}
+
+	//The first step that makes an element is the one later steps rely on
+	producers := make(map[string]model.Recipe, len(path))
+	for _, recipe := range path {
+		if _, exists := producers[recipe.Result]; !exists && recipe.Result != "" {
+			producers[recipe.Result] = recipe
+		}
+	}
+
+	building := make(map[string]bool)
+	var buildNode func(element string) *model.TreeNode
+	buildNode = func(element string) *model.TreeNode {
+		node := &model.TreeNode{Name: element}
+		recipe, ok := producers[element]
+		//Leaf: owned, not produced by the path, or a cycle back to an ancestor
+		if isBasic[element] || !ok || building[element] {
+			return node
+		}
+
+		building[element] = true
+		step := recipe
+		node.Recipe = &step
+		node.Children = []*model.TreeNode{
+			buildNode(recipe.Element1),
+			buildNode(recipe.Element2),
+		}
+		building[element] = false
+
+		return node
+	}
+
+	return buildNode(targetElement)
+}
+
+func BuildTrees(paths [][]model.Recipe, targetElement string, startElements []string) []*model.TreeNode {
+	trees := make([]*model.TreeNode, 0, len(paths))
+	for _, path := range paths {
+		trees = append(trees, BuildTree(path, targetElement, startElements))
+	}
+	return trees
+}
@@ -25,19 +25,61 @@ type Element struct {
 type SearchRequest struct {
 	StartElements []string `json:"startElements"`
 	Target        string   `json:"target"`
-	Method        string   `json:"method"`    // BFS / DFS / BIDIR
-	Mode          string   `json:"mode"`      // single / multiple
-	MaxRecipes    int      `json:"maxRecipe"` // untuk multiple
+	Targets       []string `json:"targets,omitempty"`  // beberapa target sekaligus: satu rencana gabungan
+	Method        string   `json:"method"`             // BFS / DFS / IDDFS / BIDIR / OPTIMAL / WEIGHTED / ENUM
+	Mode          string   `json:"mode"`               // single / multiple
+	MaxRecipes    int      `json:"maxRecipe"`          // untuk multiple
+	Sort          string   `json:"sort,omitempty"`     // untuk multiple: "" (urutan ditemukan) / diverse / steps / tier
+	Seed          *int64   `json:"seed,omitempty"`     // kosong = deterministik, diisi = urutan eksplorasi diacak
+	MaxDepth      int      `json:"maxDepth,omitempty"` // batas kedalaman DFS / IDDFS, 0 = tanpa batas
+
+	ExcludeElements []string `json:"excludeElements,omitempty"` // elemen yang tidak boleh dipakai sama sekali
+	ExcludeRecipes  []Recipe `json:"excludeRecipes,omitempty"`  // resep yang dilarang; result kosong = semua hasil pasangan itu
+	MustInclude     []string `json:"mustInclude,omitempty"`     // elemen yang wajib muncul di resep
+
+	MaxTier        int   `json:"maxTier,omitempty"`        // tier tertinggi untuk elemen perantara, 0 = tanpa batas
+	PreferredTiers []int `json:"preferredTiers,omitempty"` // resep dengan elemen di tier ini dicoba lebih dulu
+	RelaxTiers     bool  `json:"relaxTiers,omitempty"`     // true = bahan boleh ber-tier sama atau lebih tinggi dari hasil
+
+	// Biaya untuk method WEIGHTED: biaya elemen > biaya tier > BaseCost + TierCost * tier
+	ElementCosts map[string]float64 `json:"elementCosts,omitempty"`
+	TierCosts    map[int]float64    `json:"tierCosts,omitempty"`
+	BaseCost     *float64           `json:"baseCost,omitempty"` // kosong = 1
+	TierCost     float64            `json:"tierCost,omitempty"`
 }
 
 type SearchResult struct {
-	Recipes      [][]Recipe `json:"recipes"`
-	ElapsedTime  int64      `json:"elapsedTime"`  // dalam ms
-	VisitedNodes int        `json:"visitedNodes"` // jumlah node yang dikunjungi
+	Recipes      [][]Recipe   `json:"recipes"`
+	Trees        []*TreeNode  `json:"trees"`               // pohon resep untuk tiap elemen di Recipes
+	ElapsedTime  int64        `json:"elapsedTime"`         // dalam ms
+	VisitedNodes int          `json:"visitedNodes"`        // jumlah node yang dikunjungi
+	Optimal      *bool        `json:"optimal,omitempty"`   // khusus method OPTIMAL / WEIGHTED: true jika minimal terbukti
+	TotalCost    *float64     `json:"totalCost,omitempty"` // khusus method WEIGHTED: total biaya pohon resep
+	TotalRecipes string       `json:"totalRecipes"`        // jumlah pohon resep berbeda untuk target (bilangan besar, dalam string)
+	Reason       string       `json:"reason,omitempty"`    // penjelasan jika target tidak bisa dibuat dari StartElements
+	Plan         *PlanSummary `json:"plan,omitempty"`      // khusus permintaan dengan Targets
+
+}
+type PlanSummary struct {
+	Targets       []string `json:"targets"`
+	CombinedSteps int      `json:"combinedSteps"` // langkah rencana gabungan
+	SeparateSteps int      `json:"separateSteps"` // total langkah jika tiap target dicari sendiri
+	StepsSaved    int      `json:"stepsSaved"`
+	Missing       []string `json:"missing"` // target yang tidak ditemukan resepnya
+}
 
+type InventoryRequest struct {
+	StartElements []string `json:"startElements"` // elemen yang dimiliki, kosong = elemen dasar
 }
+
 type ElementsDatabase struct {
 	Elements map[string]Element `json:"elements"`
+	Order    []string           `json:"-"` // urutan iterasi elemen: tier lalu nama (atau diacak dengan seed)
+	Shuffled bool               `json:"-"` // true jika Order diacak dengan seed (utility.ShuffleOrder)
+
+	// Indeks yang dibangun sekali saat load (lihat utility.BuildIndex)
+	Combinations map[string][]Recipe `json:"-"` // pasangan bahan (urut) -> resep beserta Result
+	UsedIn       map[string][]Recipe `json:"-"` // elemen -> resep yang memakainya sebagai bahan
 }
 
 type ScrapeElement struct {
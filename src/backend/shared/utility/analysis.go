@@ -0,0 +1,144 @@
+package utility
+
+import (
+	"shared/model"
+	"sort"
+)
+
+type MissingIngredientRecipe struct {
+	Recipe  model.Recipe `json:"recipe"`
+	Missing []string     `json:"missing"` // ingredients that are not in the database
+}
+
+type TierViolation struct {
+	Recipe       model.Recipe `json:"recipe"`
+	ResultTier   string       `json:"resultTier"`
+	Element1Tier string       `json:"element1Tier"`
+	Element2Tier string       `json:"element2Tier"`
+}
+
+type GraphReport struct {
+	// Groups of elements that can each be made from the others, largest first.
+	// Only groups with more than one element are listed; single-element
+	// cycles are in SelfReferencing.
+	StronglyConnected  [][]string                `json:"stronglyConnected"`
+	SelfReferencing    []model.Recipe            `json:"selfReferencing"`    // the result is one of its own ingredients
+	MissingIngredients []MissingIngredientRecipe `json:"missingIngredients"` // recipes the searches can never use
+	TierViolations     []TierViolation           `json:"tierViolations"`     // ingredients not of a lower tier than the result
+}
+
+// Problems in the recipe data that the searches otherwise filter out silently.
+// The graph has an edge from each ingredient to the result of its recipe.
+// Requires BuildIndex, which LoadElementsFromFile already runs.
+func AnalyzeGraph(db *model.ElementsDatabase) *GraphReport {
+	report := &GraphReport{
+		StronglyConnected:  [][]string{},
+		SelfReferencing:    []model.Recipe{},
+		MissingIngredients: []MissingIngredientRecipe{},
+		TierViolations:     []TierViolation{},
+	}
+
+	names := db.Order
+	if len(names) != len(db.Elements) {
+		names = TierOrder(db)
+	}
+
+	for _, name := range names {
+		element := db.Elements[name]
+		for _, recipe := range element.Recipes {
+			recipe.Result = name
+			if recipe.Element1 == name || recipe.Element2 == name {
+				report.SelfReferencing = append(report.SelfReferencing, recipe)
+			}
+
+			missing := []string{}
+			for _, ingredient := range []string{recipe.Element1, recipe.Element2} {
+				if _, ok := db.Elements[ingredient]; !ok {
+					missing = append(missing, ingredient)
+				}
+			}
+			if len(missing) > 0 {
+				report.MissingIngredients = append(report.MissingIngredients, MissingIngredientRecipe{
+					Recipe:  recipe,
+					Missing: missing,
+				})
+				continue
+			}
+
+			resultTier := ParseTier(element.Tier)
+			tier1 := db.Elements[recipe.Element1].Tier
+			tier2 := db.Elements[recipe.Element2].Tier
+			if ParseTier(tier1) >= resultTier || ParseTier(tier2) >= resultTier {
+				report.TierViolations = append(report.TierViolations, TierViolation{
+					Recipe:       recipe,
+					ResultTier:   element.Tier,
+					Element1Tier: tier1,
+					Element2Tier: tier2,
+				})
+			}
+		}
+	}
+
+	for _, component := range stronglyConnected(db, names) {
+		if len(component) > 1 {
+			sort.Strings(component)
+			report.StronglyConnected = append(report.StronglyConnected, component)
+		}
+	}
+	sort.SliceStable(report.StronglyConnected, func(i, j int) bool {
+		return len(report.StronglyConnected[i]) > len(report.StronglyConnected[j])
+	})
+
+	return report
+}
+
+// Tarjan's algorithm over the ingredient -> result edges of the UsedIn index.
+func stronglyConnected(db *model.ElementsDatabase, names []string) [][]string {
+	index := make(map[string]int, len(names))
+	lowLink := make(map[string]int, len(names))
+	onStack := make(map[string]bool)
+	stack := []string{}
+	components := [][]string{}
+	next := 0
+
+	var connect func(elem string)
+	connect = func(elem string) {
+		index[elem] = next
+		lowLink[elem] = next
+		next++
+		stack = append(stack, elem)
+		onStack[elem] = true
+
+		for _, recipe := range UsesOf(db, elem) {
+			successor := recipe.Result
+			if _, seen := index[successor]; !seen {
+				connect(successor)
+				lowLink[elem] = min(lowLink[elem], lowLink[successor])
+			} else if onStack[successor] {
+				lowLink[elem] = min(lowLink[elem], index[successor])
+			}
+		}
+
+		//elem is the root of a component: pop it off the stack
+		if lowLink[elem] == index[elem] {
+			component := []string{}
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == elem {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+
+	for _, name := range names {
+		if _, seen := index[name]; !seen {
+			connect(name)
+		}
+	}
+	return components
+}
@@ -0,0 +1,53 @@
+package utility
+
+import "shared/model"
+
+// Key for an unordered ingredient pair, e.g. "Fire+Water" for both
+// Water+Fire and Fire+Water.
+func CombinationKey(e1, e2 string) string {
+	if e1 > e2 {
+		e1, e2 = e2, e1
+	}
+	return e1 + "+" + e2
+}
+
+// Build the pair -> results and "used in" indexes of the database. Every
+// indexed recipe has its Result filled in, and the lists follow db.Order.
+func BuildIndex(db *model.ElementsDatabase) {
+	db.Combinations = make(map[string][]model.Recipe)
+	db.UsedIn = make(map[string][]model.Recipe)
+
+	names := db.Order
+	if len(names) != len(db.Elements) {
+		names = TierOrder(db)
+	}
+
+	for _, name := range names {
+		for _, recipe := range db.Elements[name].Recipes {
+			recipeWithResult := model.Recipe{
+				Element1: recipe.Element1,
+				Element2: recipe.Element2,
+				Result:   name,
+			}
+
+			key := CombinationKey(recipe.Element1, recipe.Element2)
+			db.Combinations[key] = append(db.Combinations[key], recipeWithResult)
+
+			db.UsedIn[recipe.Element1] = append(db.UsedIn[recipe.Element1], recipeWithResult)
+			if recipe.Element2 != recipe.Element1 {
+				db.UsedIn[recipe.Element2] = append(db.UsedIn[recipe.Element2], recipeWithResult)
+			}
+		}
+	}
+}
+
+// All recipes (with Result) that combine e1 and e2, in either order.
+// Requires BuildIndex, which LoadElementsFromFile already runs.
+func CombinationResults(db *model.ElementsDatabase, e1, e2 string) []model.Recipe {
+	return db.Combinations[CombinationKey(e1, e2)]
+}
+
+// All recipes (with Result) that use the element as an ingredient.
+func UsesOf(db *model.ElementsDatabase, element string) []model.Recipe {
+	return db.UsedIn[element]
+}
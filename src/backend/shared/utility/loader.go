@@ -2,13 +2,19 @@ package utility
 
 import (
 	"encoding/json"
+	"fmt"
+	"math/rand"
 	"os"
 	"shared/model"
+	"sort"
 	"strconv"
 	"strings"
 )
 
-const DefaultElementsPath = "../shared/data/elements.json" // "../shared/data/elements.json" jika relatif dari `bfs/`
+const DefaultElementsPath = "../shared/data/elements.json" // "../shared/data/elements.json" jika relatif dari `server/`
+
+// Inventory awal bawaan jika request tidak menyebutkan startElements
+var DefaultStartElements = []string{"Air", "Water", "Fire", "Earth"}
 
 func LoadDatabase() *model.ElementsDatabase {
 	db, err := LoadElementsFromFile(DefaultElementsPath)
@@ -38,55 +44,106 @@ func LoadElementsFromFile(path string) (*model.ElementsDatabase, error) {
 		db.Elements[name] = elem
 	}
 
+	db.Order = TierOrder(db)
+	BuildIndex(db)
+
 	return db, nil
 }
 
-func SortByTier(db *model.ElementsDatabase) *model.ElementsDatabase {
-	orderedTiers := []string{
-		"Starting elements",
-		"Tier 1 elements",
-		"Tier 2 elements",
-		"Tier 3 elements",
-		"Tier 4 elements",
-		"Tier 5 elements",
-		"Tier 6 elements",
-		"Tier 7 elements",
-		"Tier 8 elements",
-		"Tier 9 elements",
-		"Tier 10 elements",
-		"Tier 11 elements",
-		"Tier 12 elements",
-		"Tier 13 elements",
-		"Tier 14 elements",
-		"Tier 15 elements",
-		"Special element",
+// Check that a starting inventory is non-empty and only names elements that
+// exist in the database.
+func ValidateStartElements(db *model.ElementsDatabase, elements []string) error {
+	if len(elements) == 0 {
+		return fmt.Errorf("start elements must not be empty")
 	}
-
-	orderedDb := &model.ElementsDatabase{
-		Elements: make(map[string]model.Element),
+	for _, name := range elements {
+		if _, ok := db.Elements[name]; !ok {
+			return fmt.Errorf("unknown start element %q", name)
+		}
 	}
+	return nil
+}
 
-	seen := make(map[string]bool)
+var orderedTiers = []string{
+	"Starting elements",
+	"Tier 1 elements",
+	"Tier 2 elements",
+	"Tier 3 elements",
+	"Tier 4 elements",
+	"Tier 5 elements",
+	"Tier 6 elements",
+	"Tier 7 elements",
+	"Tier 8 elements",
+	"Tier 9 elements",
+	"Tier 10 elements",
+	"Tier 11 elements",
+	"Tier 12 elements",
+	"Tier 13 elements",
+	"Tier 14 elements",
+	"Tier 15 elements",
+	"Special element",
+}
 
-	for _, tier := range orderedTiers {
-		for name, elem := range db.Elements {
-			if elem.Tier == tier && !seen[name] {
-				orderedDb.Elements[name] = elem
-				seen[name] = true
-			}
+// Element names ordered by tier, then by name. Elements without a known tier
+// come last.
+func TierOrder(db *model.ElementsDatabase) []string {
+	rank := make(map[string]int, len(orderedTiers))
+	for i, tier := range orderedTiers {
+		rank[tier] = i
+	}
+	tierRank := func(name string) int {
+		if r, ok := rank[db.Elements[name].Tier]; ok {
+			return r
 		}
+		return len(orderedTiers)
 	}
 
-	// Tambahkan elemen yang tidak memiliki tier atau tidak termasuk dalam daftar tier
-	for name, elem := range db.Elements {
-		if !seen[name] {
-			orderedDb.Elements[name] = elem
+	names := make([]string, 0, len(db.Elements))
+	for name := range db.Elements {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ri, rj := tierRank(names[i]), tierRank(names[j])
+		if ri != rj {
+			return ri < rj
 		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// Copy of the database whose Order (and therefore every index) follows tier,
+// then name.
+func SortByTier(db *model.ElementsDatabase) *model.ElementsDatabase {
+	orderedDb := &model.ElementsDatabase{
+		Elements: make(map[string]model.Element, len(db.Elements)),
 	}
+	for name, elem := range db.Elements {
+		orderedDb.Elements[name] = elem
+	}
+	orderedDb.Order = TierOrder(orderedDb)
+	BuildIndex(orderedDb)
 
 	return orderedDb
 }
 
+// Copy of the database with a seeded random Order, for deliberately
+// randomized exploration. The same seed always gives the same order.
+func ShuffleOrder(db *model.ElementsDatabase, seed int64) *model.ElementsDatabase {
+	shuffled := &model.ElementsDatabase{
+		Elements: db.Elements,
+		Order:    TierOrder(db),
+		Shuffled: true,
+	}
+	rng := rand.New(rand.NewSource(seed))
+	rng.Shuffle(len(shuffled.Order), func(i, j int) {
+		shuffled.Order[i], shuffled.Order[j] = shuffled.Order[j], shuffled.Order[i]
+	})
+	BuildIndex(shuffled)
+
+	return shuffled
+}
+
 func ParseTier(tierStr string) int {
 	if strings.HasPrefix(tierStr, "Tier ") {
 		// contoh: "Tier 3 Element"
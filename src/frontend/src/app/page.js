@@ -4,6 +4,7 @@ import ElementCardSelector from "../components/ElementCardSelector";
 import SearchButton from "../components/SearchButton";
 import dynamic from "next/dynamic";
 const ResultTree = dynamic(() => import("../components/ResultTree"), { ssr: false });
+const API_URL = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080";
 
 
 export default function Home() {
@@ -12,14 +13,16 @@ export default function Home() {
   const [method, setMethod] = useState("BFS");
   const [mode, setMode] = useState("single");
   const [maxRecipe, setMaxRecipe] = useState(3);
+  const [sort, setSort] = useState("");
   const [result, setResult] = useState([]);
+  const [trees, setTrees] = useState([]);
   const [elapsedTime, setElapsedTime] = useState("");
   const [visitedNodes, setVisitedNodes] = useState("");
   const [isLoading, setIsLoading] = useState(false); 
 
   useEffect(() => {
     setIsLoading(true);
-    fetch("http://localhost:8081/elements-info")
+    fetch(`${API_URL}/elements-info`)
       .then((res) => {
         if (!res.ok) throw new Error(`Gagal memuat info elemen: ${res.statusText}`);
         return res.json();
@@ -30,10 +33,10 @@ export default function Home() {
       .catch((error) => {
         console.error("Gagal memuat data dari backend:", error);
         setAllElements([
-          { name: "Air", imagePath: "http://localhost:8081/images/air.png", tier: "Starting elements" },
-          { name: "Water", imagePath: "http://localhost:8081/images/water.png", tier: "Starting elements" },
-          { name: "Fire", imagePath: "http://localhost:8081/images/fire.png", tier: "Starting elements" },
-          { name: "Earth", imagePath: "http://localhost:8081/images/earth.png", tier: "Starting elements" },
+          { name: "Air", imagePath: `${API_URL}/images/air.png`, tier: "Starting elements" },
+          { name: "Water", imagePath: `${API_URL}/images/water.png`, tier: "Starting elements" },
+          { name: "Fire", imagePath: `${API_URL}/images/fire.png`, tier: "Starting elements" },
+          { name: "Earth", imagePath: `${API_URL}/images/earth.png`, tier: "Starting elements" },
         ]);
       })
       .finally(() => {
@@ -46,17 +49,16 @@ export default function Home() {
     if (!target) return;
     setIsLoading(true);
     setResult([]); 
+    setTrees([]);
 
-    const backendURL =
-      method === "BFS"
-        ? "http://localhost:8081/search"
-        : "http://localhost:8082/search"; 
+    const backendURL = `${API_URL}/search`;
 
     const body = {
       target,
       method,
       mode,
       ...(mode === "multiple" && { maxRecipe: Number(maxRecipe) }),
+      ...(mode === "multiple" && sort && { sort }),
     };
 
     try {
@@ -73,6 +75,7 @@ export default function Home() {
 
       const data = await res.json();
       setResult(data.recipes && data.recipes.length > 0 ? data.recipes : ["Resep tidak ditemukan atau format tidak sesuai."]);
+      setTrees(data.trees || []);
       setElapsedTime(data.elapsedTime);
       setVisitedNodes(data.visitedNodes);
     } catch (err) {
@@ -110,6 +113,10 @@ export default function Home() {
                 >
                 <option value="BFS">BFS</option>
                 <option value="DFS">DFS</option>
+                <option value="IDDFS">IDDFS</option>
+                <option value="BIDIR">Bidirectional</option>
+                <option value="OPTIMAL">Optimal (kombinasi minimum)</option>
+                <option value="ENUM">Enumerasi pohon resep</option>
               </select>
             </div>
 
@@ -139,6 +146,22 @@ export default function Home() {
               </div>
             )}
 
+            {mode === "multiple" && (
+              <div className="mb-5">
+                <label className="block mb-1.5 text-gray-700 font-medium">Urutan Hasil</label>
+                <select
+                  value={sort}
+                  onChange={(e) => setSort(e.target.value)}
+                  className="w-full p-2.5 border border-gray-300 rounded-md shadow-sm focus:ring-indigo-500 focus:border-indigo-500"
+                >
+                  <option value="">Urutan ditemukan</option>
+                  <option value="diverse">Paling beragam</option>
+                  <option value="steps">Langkah paling sedikit</option>
+                  <option value="tier">Tier tertinggi paling rendah</option>
+                </select>
+              </div>
+            )}
+
             <SearchButton
               label={isLoading ? "Mencari..." : `Cari Resep (${method})`}
               onClick={handleSearch}
@@ -158,6 +181,7 @@ export default function Home() {
               <ResultTree
                 targetElement={target}
                 recipeSteps={result}
+                tree={trees[0]}
                 time={elapsedTime}
                 nodes={visitedNodes}
                 elementImages={Object.fromEntries(allElements.map(el => [el.name, el.imagePath]))}
@@ -22,7 +22,7 @@ const StyledNode = styled.div`
   }
 `;
 
-export default function ResultTree({ targetElement, recipeSteps, time, nodes, elementImages }) {
+export default function ResultTree({ targetElement, recipeSteps, tree, time, nodes, elementImages }) {
   if (!recipeSteps || recipeSteps.length === 0 || typeof recipeSteps[0] === "string") {
     return (
       <div className="mt-4 text-sm text-gray-700">
@@ -34,25 +34,8 @@ export default function ResultTree({ targetElement, recipeSteps, time, nodes, el
     );
   }
 
-  const actualRecipePath = Array.isArray(recipeSteps[0]) ? recipeSteps[0] : recipeSteps;
-
-  const buildTreeFromSteps = (target, steps) => {
-    const map = new Map();
-    steps.forEach(step => map.set(step.result, step));
-
-    const buildNode = (element) => {
-      const step = map.get(element);
-      if (!step) return { name: element, children: [] };
-      return {
-        name: element,
-        children: [buildNode(step.element1), buildNode(step.element2)],
-      };
-    };
-
-    return buildNode(target);
-  };
-
-  const treeData = buildTreeFromSteps(targetElement, actualRecipePath);
+  // Pohon resep sudah dibangun oleh backend (field "trees" pada respons /search)
+  const treeData = tree || { name: targetElement, children: [] };
 
   const renderTree = (node, isRoot = false, key = null) => {
     if (!node || !node.name) return null; // ⛑️ Penjagaan penting